@@ -0,0 +1,390 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var (
+	ErrUnknownPair       = errors.New("event of an unknown pair")
+	ErrDuplicateStrategy = errors.New("strategy name is used twice")
+	ErrSlippage          = errors.New("fill exceeds the allowed slippage")
+	ErrNotFilled         = errors.New("replay ended before the trade was filled")
+)
+
+// Strategy submits trades as blocks are replayed
+type Strategy interface {
+	Name() string // Names the strategy in the report, unique within a run
+	OnBlock(block *Block) error
+}
+
+type strategyFunc struct {
+	name    string
+	onBlock func(block *Block) error
+}
+
+func (s *strategyFunc) Name() string {
+	return s.name
+}
+
+func (s *strategyFunc) OnBlock(block *Block) error {
+	return s.onBlock(block)
+}
+
+// NewStrategy returns the strategy calling onBlock at every block
+func NewStrategy(name string, onBlock func(block *Block) error) Strategy {
+	return &strategyFunc{name: name, onBlock: onBlock}
+}
+
+// Block is the state a strategy sees after the events of a block are applied
+type Block struct {
+	Number uint64
+	Events []*Event // The events of the block
+
+	engine   *Engine
+	strategy string
+}
+
+// Pairs returns the pairs, in the order they were added
+func (b *Block) Pairs() []*entities.Pair {
+	return b.engine.Pairs()
+}
+
+// Pair returns the pair of the address, or nil
+func (b *Block) Pair(address common.Address) *entities.Pair {
+	return b.engine.pairs[address]
+}
+
+// Submit queues the trade, quoted against the pairs of the block. It is filled in the next replayed block, after
+// its events, and reverts if it does worse than the quote by more than the slippage.
+func (b *Block) Submit(trade *entities.Trade, slippage *core.Percent) error {
+	if _, err := trade.MinimumAmountOut(slippage); err != nil {
+		return err
+	}
+	b.engine.pending = append(b.engine.pending, &order{
+		strategy: b.strategy,
+		block:    b.Number,
+		trade:    trade,
+		slippage: slippage,
+	})
+	return nil
+}
+
+type order struct {
+	strategy string
+	block    uint64
+	trade    *entities.Trade
+	slippage *core.Percent
+}
+
+// Fill is the outcome of a submitted trade
+type Fill struct {
+	Strategy  string
+	Block     uint64          // The block the trade was submitted at
+	FillBlock uint64          // The block the trade was filled at
+	Trade     *entities.Trade // The quote
+	Slippage  *core.Percent   // The allowed slippage
+
+	AmountIn  *core.CurrencyAmount // The filled input, nil if reverted
+	AmountOut *core.CurrencyAmount // The filled output, nil if reverted
+	// How much worse than the quote the fill is, on the output of exact input trades and on the input of exact
+	// output trades. Negative if it is better. Nil if reverted.
+	QuoteSlippage *core.Percent
+	Err           error // Why the trade reverted, nil if filled
+}
+
+// StrategyReport sums up the fills of a strategy
+type StrategyReport struct {
+	Name     string
+	Fills    []*Fill
+	Filled   int
+	Reverted int
+	Balances map[common.Address]*core.CurrencyAmount // Net amount of each token traded, negative for sold tokens
+	// Value of the balances in the numeraire at the mid prices of the last block, nil without a numeraire
+	PnL      *core.CurrencyAmount
+	Unpriced []*core.Token // Tokens with no route to the numeraire, left out of the PnL
+}
+
+// Report of a replay
+type Report struct {
+	FirstBlock uint64
+	LastBlock  uint64
+	Blocks     int // The number of blocks replayed
+	Strategies []*StrategyReport
+}
+
+// Engine replays pair events and fills the trades of strategies against the replayed pairs.
+// Sync events reset the reserves of a pair to the recorded ones, so fills move a pair only until its next Sync.
+type Engine struct {
+	ChainID   uint
+	Numeraire *core.Token           // The token PnL is valued in. Optional.
+	Options   *entities.PairOptions // Factory of the created pairs. The recorded pair address is kept.
+
+	pairs   map[common.Address]*entities.Pair
+	order   []common.Address
+	pending []*order
+}
+
+// NewEngine creates an engine starting from the pairs, e.g. those existing before the first recorded block
+func NewEngine(chainID uint, numeraire *core.Token, pairs ...*entities.Pair) *Engine {
+	e := &Engine{ChainID: chainID, Numeraire: numeraire, pairs: map[common.Address]*entities.Pair{}}
+	for _, pair := range pairs {
+		e.setPair(pair)
+	}
+	return e
+}
+
+// Pairs returns the pairs, in the order they were added
+func (e *Engine) Pairs() []*entities.Pair {
+	pairs := make([]*entities.Pair, len(e.order))
+	for i, address := range e.order {
+		pairs[i] = e.pairs[address]
+	}
+	return pairs
+}
+
+func (e *Engine) setPair(pair *entities.Pair) {
+	if _, ok := e.pairs[pair.Address]; !ok {
+		e.order = append(e.order, pair.Address)
+	}
+	e.pairs[pair.Address] = pair
+}
+
+func (e *Engine) newPair(address common.Address, amount0, amount1 *core.CurrencyAmount) (*entities.Pair, error) {
+	options := &entities.PairOptions{Address: &address}
+	if e.Options != nil {
+		options.Factory, options.InitCodeHash = e.Options.Factory, e.Options.InitCodeHash
+	}
+	return entities.NewPair(amount0, amount1, options)
+}
+
+// apply updates the pairs with the event. Swap, Mint and Burn only inform strategies, their Sync sets the reserves.
+func (e *Engine) apply(event *Event) error {
+	switch event.Type {
+	case PairCreated:
+		token0 := core.NewToken(e.ChainID, event.Token0.Address, event.Token0.Decimals, event.Token0.Symbol, event.Token0.Name)
+		token1 := core.NewToken(e.ChainID, event.Token1.Address, event.Token1.Decimals, event.Token1.Symbol, event.Token1.Name)
+		pair, err := e.newPair(event.Pair, core.FromRawAmount(token0, big.NewInt(0)), core.FromRawAmount(token1, big.NewInt(0)))
+		if err != nil {
+			return err
+		}
+		e.setPair(pair)
+	case Sync:
+		pair := e.pairs[event.Pair]
+		if pair == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownPair, event.Pair.Hex())
+		}
+		next, err := entities.NewPair(
+			core.FromRawAmount(pair.Token0(), event.Reserve0),
+			core.FromRawAmount(pair.Token1(), event.Reserve1),
+			pair.Options,
+		)
+		if err != nil {
+			return err
+		}
+		e.setPair(next)
+	}
+	return nil
+}
+
+// Run replays the events, ordered by block, and calls the strategies at every block.
+// Trades still pending after the last block are reported with ErrNotFilled. Returns ErrInvalidEvent or
+// ErrUnorderedEvents before replaying anything if an event is invalid or out of order.
+func (e *Engine) Run(events []*Event, strategies ...Strategy) (*Report, error) {
+	report := &Report{}
+	reports := map[string]*StrategyReport{}
+	for _, strategy := range strategies {
+		if reports[strategy.Name()] != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateStrategy, strategy.Name())
+		}
+		reports[strategy.Name()] = &StrategyReport{Name: strategy.Name(), Balances: map[common.Address]*core.CurrencyAmount{}}
+		report.Strategies = append(report.Strategies, reports[strategy.Name()])
+	}
+	for i, event := range events {
+		if err := event.Validate(); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		if i > 0 && event.before(events[i-1]) {
+			return nil, fmt.Errorf("%w: event %d", ErrUnorderedEvents, i)
+		}
+	}
+
+	for i := 0; i < len(events); {
+		number := events[i].Block
+		j := i
+		for j < len(events) && events[j].Block == number {
+			if err := e.apply(events[j]); err != nil {
+				return nil, fmt.Errorf("block %d: %w", number, err)
+			}
+			j++
+		}
+		if report.Blocks == 0 {
+			report.FirstBlock = number
+		}
+		report.LastBlock = number
+		report.Blocks++
+
+		pending := e.pending
+		e.pending = nil
+		for _, o := range pending {
+			reports[o.strategy].add(e.fill(o, number))
+		}
+		for _, strategy := range strategies {
+			block := &Block{Number: number, Events: events[i:j], engine: e, strategy: strategy.Name()}
+			if err := strategy.OnBlock(block); err != nil {
+				return nil, fmt.Errorf("strategy %s at block %d: %w", strategy.Name(), number, err)
+			}
+		}
+		i = j
+	}
+	for _, o := range e.pending {
+		reports[o.strategy].add(&Fill{Strategy: o.strategy, Block: o.block, Trade: o.trade, Slippage: o.slippage, Err: ErrNotFilled})
+	}
+	e.pending = nil
+
+	if e.Numeraire != nil {
+		for _, r := range report.Strategies {
+			e.valuate(r)
+		}
+	}
+	return report, nil
+}
+
+// fill executes the order against the current pairs, along the route of its trade
+func (e *Engine) fill(o *order, number uint64) *Fill {
+	f := &Fill{Strategy: o.strategy, Block: o.block, FillBlock: number, Trade: o.trade, Slippage: o.slippage}
+	route := o.trade.Route
+	hops := len(route.Pairs)
+	amounts := make([]*big.Int, hops+1)
+	nextPairs := make([]*entities.Pair, hops)
+	exactIn := o.trade.TradeType == entities.ExactInput
+	if exactIn {
+		amounts[0] = o.trade.InputAmount().Quotient()
+	} else {
+		amounts[hops] = o.trade.OutputAmount().Quotient()
+	}
+	for h := 0; h < hops; h++ {
+		i := h
+		if !exactIn {
+			i = hops - 1 - h
+		}
+		pair := e.pairs[route.Pairs[i].Address]
+		if pair == nil {
+			f.Err = fmt.Errorf("%w: %s", ErrUnknownPair, route.Pairs[i].Address.Hex())
+			return f
+		}
+		var (
+			amount *core.CurrencyAmount
+			err    error
+		)
+		if exactIn {
+			amount, nextPairs[i], err = pair.GetOutputAmount(core.FromRawAmount(route.Path[i], amounts[i]))
+			if err == nil {
+				amounts[i+1] = amount.Quotient()
+			}
+		} else {
+			amount, nextPairs[i], err = pair.GetInputAmount(core.FromRawAmount(route.Path[i+1], amounts[i+1]))
+			if err == nil {
+				amounts[i] = amount.Quotient()
+			}
+		}
+		if err != nil {
+			f.Err = err
+			return f
+		}
+	}
+
+	quoteIn, quoteOut := o.trade.InputAmount().Quotient(), o.trade.OutputAmount().Quotient()
+	amountIn, amountOut := amounts[0], amounts[hops]
+	if exactIn {
+		minimum, _ := o.trade.MinimumAmountOut(o.slippage)
+		if amountOut.Cmp(minimum.Quotient()) < 0 {
+			f.Err = ErrSlippage
+			return f
+		}
+		f.QuoteSlippage = core.NewPercent(new(big.Int).Sub(quoteOut, amountOut), quoteOut)
+	} else {
+		maximum, _ := o.trade.MaximumAmountIn(o.slippage)
+		if amountIn.Cmp(maximum.Quotient()) > 0 {
+			f.Err = ErrSlippage
+			return f
+		}
+		f.QuoteSlippage = core.NewPercent(new(big.Int).Sub(amountIn, quoteIn), quoteIn)
+	}
+	for _, pair := range nextPairs {
+		e.setPair(pair)
+	}
+	f.AmountIn = core.FromRawAmount(o.trade.InputAmount().Currency, amountIn)
+	f.AmountOut = core.FromRawAmount(o.trade.OutputAmount().Currency, amountOut)
+	return f
+}
+
+func (r *StrategyReport) add(f *Fill) {
+	r.Fills = append(r.Fills, f)
+	if f.Err != nil {
+		r.Reverted++
+		return
+	}
+	r.Filled++
+	r.credit(entities.WrappedOf(f.AmountIn.Currency), new(big.Int).Neg(f.AmountIn.Quotient()))
+	r.credit(entities.WrappedOf(f.AmountOut.Currency), f.AmountOut.Quotient())
+}
+
+func (r *StrategyReport) credit(token *core.Token, amount *big.Int) {
+	balance := amount
+	if known := r.Balances[token.Address]; known != nil {
+		balance = new(big.Int).Add(known.Quotient(), amount)
+	}
+	r.Balances[token.Address] = core.FromRawAmount(token, balance)
+}
+
+// valuate sets the PnL of the report from the mid price of the best route of each balance to the numeraire
+func (e *Engine) valuate(r *StrategyReport) {
+	pnl := big.NewInt(0)
+	addresses := make([]common.Address, 0, len(r.Balances))
+	for address := range r.Balances {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Hex() < addresses[j].Hex() })
+	for _, address := range addresses {
+		balance := r.Balances[address]
+		value, ok := e.value(balance)
+		if !ok {
+			r.Unpriced = append(r.Unpriced, entities.WrappedOf(balance.Currency))
+			continue
+		}
+		pnl.Add(pnl, value)
+	}
+	r.PnL = core.FromRawAmount(e.Numeraire, pnl)
+}
+
+func (e *Engine) value(balance *core.CurrencyAmount) (*big.Int, bool) {
+	amount, token := balance.Quotient(), entities.WrappedOf(balance.Currency)
+	if amount.Sign() == 0 || token.Equal(e.Numeraire) {
+		return amount, true
+	}
+	abs := core.FromRawAmount(token, new(big.Int).Abs(amount))
+	trades, err := entities.BestTradeExactIn(e.Pairs(), abs, e.Numeraire,
+		&entities.BestTradeOptions{MaxNumResults: 1, MaxHops: 3}, nil, nil, nil)
+	if err != nil || len(trades) == 0 {
+		return nil, false
+	}
+	price, err := trades[0].Route.MidPrice()
+	if err != nil {
+		return nil, false
+	}
+	value, err := price.Quote(abs)
+	if err != nil {
+		return nil, false
+	}
+	if amount.Sign() < 0 {
+		return new(big.Int).Neg(value.Quotient()), true
+	}
+	return value.Quotient(), true
+}
@@ -0,0 +1,104 @@
+package backtest_test
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/backtest"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var pairAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+// buyAt returns a strategy selling 1000 t0 for t1 at the block
+func buyAt(name string, number uint64, slippage *core.Percent) backtest.Strategy {
+	return backtest.NewStrategy(name, func(block *backtest.Block) error {
+		if block.Number != number {
+			return nil
+		}
+		pair := block.Pair(pairAddress)
+		route, err := entities.NewRoute([]*entities.Pair{pair}, pair.Token0(), pair.Token1())
+		if err != nil {
+			return err
+		}
+		trade, err := entities.ExactIn(route, core.FromRawAmount(pair.Token0(), big.NewInt(1000)))
+		if err != nil {
+			return err
+		}
+		return block.Submit(trade, slippage)
+	})
+}
+
+func TestEngine(t *testing.T) {
+	events, err := backtest.ReadEvents(strings.NewReader(recorded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	numeraire := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000001"), 18, "t0", "")
+	engine := backtest.NewEngine(1, numeraire)
+
+	var seen []uint64
+	watch := backtest.NewStrategy("watch", func(block *backtest.Block) error {
+		seen = append(seen, block.Number)
+		return nil
+	})
+	loose := buyAt("loose", 1, core.NewPercent(big.NewInt(25), big.NewInt(100)))
+	tight := buyAt("tight", 1, core.NewPercent(big.NewInt(0), big.NewInt(100)))
+	late := buyAt("late", 4, core.NewPercent(big.NewInt(1), big.NewInt(100)))
+
+	report, err := engine.Run(events, watch, loose, tight, late)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Blocks != 3 || report.FirstBlock != 1 || report.LastBlock != 4 || len(seen) != 3 {
+		t.Errorf("expect[3 blocks from 1 to 4], but got[%d from %d to %d, seen %v]", report.Blocks, report.FirstBlock, report.LastBlock, seen)
+	}
+
+	// quoted 996 at block 1, filled 823 after the swap of block 2
+	r := report.Strategies[1]
+	if r.Filled != 1 || r.Reverted != 0 {
+		t.Fatalf("expect[1 filled], but got[%d filled, %d reverted %v]", r.Filled, r.Reverted, r.Fills[0].Err)
+	}
+	fill := r.Fills[0]
+	if fill.Block != 1 || fill.FillBlock != 2 || fill.Trade.OutputAmount().Quotient().String() != "996" || fill.AmountOut.Quotient().String() != "823" {
+		t.Errorf("expect[996 quoted at 1, 823 filled at 2], but got[%s at %d, %s at %d]",
+			fill.Trade.OutputAmount().Quotient(), fill.Block, fill.AmountOut.Quotient(), fill.FillBlock)
+	}
+	if fill.QuoteSlippage.ToFixed(2) != "17.37" {
+		t.Errorf("expect[17.37], but got[%s]", fill.QuoteSlippage.ToFixed(2))
+	}
+	if r.Balances[numeraire.Address].Quotient().String() != "-1000" {
+		t.Errorf("expect[-1000], but got[%s]", r.Balances[numeraire.Address].Quotient())
+	}
+	// 823 t1 is worth 823 t0 at the 1:1 price of block 4
+	if r.PnL.Quotient().String() != "-177" || len(r.Unpriced) != 0 {
+		t.Errorf("expect[-177], but got[%s %v]", r.PnL.Quotient(), r.Unpriced)
+	}
+
+	// the fill of loose moved the pair, tight reverts
+	if r := report.Strategies[2]; r.Reverted != 1 || !errors.Is(r.Fills[0].Err, backtest.ErrSlippage) || r.PnL.Quotient().Sign() != 0 {
+		t.Errorf("expect[%v], but got[%+v]", backtest.ErrSlippage, r.Fills[0])
+	}
+	if r := report.Strategies[3]; r.Reverted != 1 || !errors.Is(r.Fills[0].Err, backtest.ErrNotFilled) {
+		t.Errorf("expect[%v], but got[%+v]", backtest.ErrNotFilled, r.Fills[0])
+	}
+
+	if _, err := backtest.NewEngine(1, nil).Run(events, watch, watch); !errors.Is(err, backtest.ErrDuplicateStrategy) {
+		t.Errorf("expect[%v], but got[%v]", backtest.ErrDuplicateStrategy, err)
+	}
+	if _, err := backtest.NewEngine(1, nil).Run(events[1:]); !errors.Is(err, backtest.ErrUnknownPair) {
+		t.Errorf("expect[%v], but got[%v]", backtest.ErrUnknownPair, err)
+	}
+	unordered := []*backtest.Event{events[0], events[3], events[1]}
+	if _, err := backtest.NewEngine(1, nil).Run(unordered, watch); !errors.Is(err, backtest.ErrUnorderedEvents) {
+		t.Errorf("expect[%v], but got[%v]", backtest.ErrUnorderedEvents, err)
+	}
+	oversized := &backtest.Event{Block: 3, Type: backtest.Sync, Pair: pairAddress, Reserve0: new(big.Int).Lsh(big.NewInt(1), 256), Reserve1: big.NewInt(1)}
+	if _, err := backtest.NewEngine(1, nil).Run([]*backtest.Event{events[0], oversized}, watch); !errors.Is(err, backtest.ErrInvalidEvent) {
+		t.Errorf("expect[%v], but got[%v]", backtest.ErrInvalidEvent, err)
+	}
+}
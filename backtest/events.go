@@ -0,0 +1,142 @@
+// Package backtest replays recorded Uniswap V2 pair events block by block and fills the trades of strategies
+// against the replayed pair state, reporting fills, slippage versus quote and PnL per strategy.
+package backtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrInvalidEvent    = errors.New("invalid pair event")
+	ErrUnorderedEvents = errors.New("pair events are not ordered by block")
+)
+
+// EventType is the name of a pair or factory event
+type EventType string
+
+const (
+	PairCreated EventType = "PairCreated"
+	Sync        EventType = "Sync"
+	Swap        EventType = "Swap"
+	Mint        EventType = "Mint"
+	Burn        EventType = "Burn"
+)
+
+// TokenInfo describes a token of a created pair
+type TokenInfo struct {
+	Address  common.Address `json:"address"`
+	Decimals uint           `json:"decimals"`
+	Symbol   string         `json:"symbol,omitempty"`
+	Name     string         `json:"name,omitempty"`
+}
+
+// Event is a recorded pair event. Amounts are JSON numbers from 0 to 2^256-1.
+type Event struct {
+	Block    uint64         `json:"block"`
+	LogIndex uint           `json:"logIndex"`
+	Type     EventType      `json:"type"`
+	Pair     common.Address `json:"pair"`
+
+	// PairCreated
+	Token0 *TokenInfo `json:"token0,omitempty"`
+	Token1 *TokenInfo `json:"token1,omitempty"`
+
+	// Sync, the reserves after the block's swap, mint or burn
+	Reserve0 *big.Int `json:"reserve0,omitempty"`
+	Reserve1 *big.Int `json:"reserve1,omitempty"`
+
+	// Swap
+	Amount0In  *big.Int `json:"amount0In,omitempty"`
+	Amount1In  *big.Int `json:"amount1In,omitempty"`
+	Amount0Out *big.Int `json:"amount0Out,omitempty"`
+	Amount1Out *big.Int `json:"amount1Out,omitempty"`
+
+	// Mint and Burn
+	Amount0 *big.Int `json:"amount0,omitempty"`
+	Amount1 *big.Int `json:"amount1,omitempty"`
+}
+
+// Validate checks the event has the fields of its type, with amounts in the uint256 range
+func (e *Event) Validate() error {
+	var missing bool
+	var amounts []*big.Int
+	switch e.Type {
+	case PairCreated:
+		missing = e.Token0 == nil || e.Token1 == nil
+	case Sync:
+		amounts = []*big.Int{e.Reserve0, e.Reserve1}
+	case Swap:
+		amounts = []*big.Int{e.Amount0In, e.Amount1In, e.Amount0Out, e.Amount1Out}
+	case Mint, Burn:
+		amounts = []*big.Int{e.Amount0, e.Amount1}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+	for _, amount := range amounts {
+		missing = missing || amount == nil
+	}
+	if missing {
+		return fmt.Errorf("%w: %s of %s misses amounts or tokens", ErrInvalidEvent, e.Type, e.Pair.Hex())
+	}
+	for _, amount := range amounts {
+		if amount.Sign() < 0 || amount.Cmp(core.MaxUint256) > 0 {
+			return fmt.Errorf("%w: %s of %s has an amount of %s out of the uint256 range", ErrInvalidEvent, e.Type, e.Pair.Hex(), amount)
+		}
+	}
+	if e.Type == PairCreated && (e.Token0.Decimals >= 255 || e.Token1.Decimals >= 255) {
+		return fmt.Errorf("%w: %s of %s has tokens of 255 or more decimals", ErrInvalidEvent, e.Type, e.Pair.Hex())
+	}
+	return nil
+}
+
+// before tells whether the event comes before next, by block and log index
+func (e *Event) before(next *Event) bool {
+	return e.Block < next.Block || (e.Block == next.Block && e.LogIndex < next.LogIndex)
+}
+
+// ReadEvents decodes and validates events written one JSON object per line, ordered by block and log index.
+// Blank lines are skipped.
+func ReadEvents(r io.Reader) ([]*Event, error) {
+	var events []*Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidEvent, line, err)
+		}
+		if err := event.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if n := len(events); n > 0 && event.before(events[n-1]) {
+			return nil, fmt.Errorf("%w: line %d", ErrUnorderedEvents, line)
+		}
+		events = append(events, &event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// LoadEvents reads the events of a local file, see ReadEvents
+func LoadEvents(path string) ([]*Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadEvents(f)
+}
@@ -0,0 +1,62 @@
+package backtest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vaulverin/uniswapv2-sdk/backtest"
+)
+
+const recorded = `{"block":1,"logIndex":0,"type":"PairCreated","pair":"0x00000000000000000000000000000000000000aa","token0":{"address":"0x0000000000000000000000000000000000000001","decimals":18,"symbol":"t0"},"token1":{"address":"0x0000000000000000000000000000000000000002","decimals":18,"symbol":"t1"}}
+{"block":1,"logIndex":1,"type":"Sync","pair":"0x00000000000000000000000000000000000000aa","reserve0":1000000,"reserve1":1000000}
+{"block":1,"logIndex":2,"type":"Mint","pair":"0x00000000000000000000000000000000000000aa","amount0":1000000,"amount1":1000000}
+
+{"block":2,"logIndex":0,"type":"Sync","pair":"0x00000000000000000000000000000000000000aa","reserve0":1100000,"reserve1":909339}
+{"block":2,"logIndex":1,"type":"Swap","pair":"0x00000000000000000000000000000000000000aa","amount0In":100000,"amount1In":0,"amount0Out":0,"amount1Out":90661}
+{"block":4,"logIndex":0,"type":"Sync","pair":"0x00000000000000000000000000000000000000aa","reserve0":1000000,"reserve1":1000000}
+`
+
+func TestReadEvents(t *testing.T) {
+	events, err := backtest.ReadEvents(strings.NewReader(recorded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 6 {
+		t.Fatalf("expect[6], but got[%d]", len(events))
+	}
+	if events[0].Token1.Symbol != "t1" || events[3].Reserve1.String() != "909339" || events[4].Amount1Out.String() != "90661" {
+		t.Errorf("expect[t1 909339 90661], but got[%s %s %s]", events[0].Token1.Symbol, events[3].Reserve1, events[4].Amount1Out)
+	}
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(recorded), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if events, err = backtest.LoadEvents(path); err != nil || len(events) != 6 {
+		t.Errorf("expect[6], but got[%d %v]", len(events), err)
+	}
+
+	tests := []struct {
+		Name  string
+		Lines string
+		Err   error
+	}{
+		{"unknown type", `{"block":1,"type":"Skim"}`, backtest.ErrInvalidEvent},
+		{"missing reserves", `{"block":1,"type":"Sync","reserve0":1}`, backtest.ErrInvalidEvent},
+		{"malformed", `{"block":"one"}`, backtest.ErrInvalidEvent},
+		{"negative reserve", `{"block":1,"type":"Sync","reserve0":-1,"reserve1":1}`, backtest.ErrInvalidEvent},
+		{"reserve above uint256", `{"block":1,"type":"Sync","reserve0":115792089237316195423570985008687907853269984665640564039457584007913129639936,"reserve1":1}`, backtest.ErrInvalidEvent},
+		{"negative swap amount", `{"block":1,"type":"Swap","amount0In":1,"amount1In":0,"amount0Out":0,"amount1Out":-1}`, backtest.ErrInvalidEvent},
+		{"mint above uint256", `{"block":1,"type":"Mint","amount0":115792089237316195423570985008687907853269984665640564039457584007913129639936,"amount1":1}`, backtest.ErrInvalidEvent},
+		{"decimals", `{"block":1,"type":"PairCreated","token0":{"decimals":255},"token1":{"decimals":18}}`, backtest.ErrInvalidEvent},
+		{"unordered", `{"block":2,"type":"Mint","amount0":1,"amount1":1}` + "\n" + `{"block":1,"type":"Mint","amount0":1,"amount1":1}`, backtest.ErrUnorderedEvents},
+	}
+	for _, test := range tests {
+		if _, err := backtest.ReadEvents(strings.NewReader(test.Lines)); !errors.Is(err, test.Err) {
+			t.Errorf("%s: expect[%v], but got[%v]", test.Name, test.Err, err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package discovery enumerates the pairs of a Uniswap V2 factory, by index or from its PairCreated logs, and
+// resolves their tokens into a catalog of pairs for BestTradeExactIn/Out. Discovery resumes from the checkpoint
+// of the catalog, and reads the chain through interfaces that can be stubbed offline.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/snapshot"
+)
+
+// DefaultBlockRange is the most blocks queried for logs at once
+const DefaultBlockRange uint64 = 5000
+
+var (
+	ErrNoSource     = errors.New("discoverer has no source for the operation")
+	ErrInvalidToken = errors.New("token has 255 or more decimals")
+)
+
+// Checkpoint tells where discovery resumes
+type Checkpoint struct {
+	NextIndex uint64 `json:"nextIndex"` // The next allPairs index to enumerate
+	NextBlock uint64 `json:"nextBlock"` // The next block to scan for PairCreated logs
+}
+
+// Catalog holds the tokens and pairs discovered so far
+type Catalog struct {
+	ChainID    uint
+	Checkpoint Checkpoint
+	Tokens     map[common.Address]*core.Token
+	Skipped    map[common.Address]error // Pairs left out because their tokens or reserves could not be read
+
+	pairs map[common.Address]*entities.Pair
+	order []common.Address
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog(chainID uint) *Catalog {
+	return &Catalog{
+		ChainID: chainID,
+		Tokens:  map[common.Address]*core.Token{},
+		Skipped: map[common.Address]error{},
+		pairs:   map[common.Address]*entities.Pair{},
+	}
+}
+
+// FromSnapshot creates a catalog of the pairs of a full snapshot, resuming from the checkpoint
+func FromSnapshot(s *snapshot.Snapshot, checkpoint Checkpoint) (*Catalog, error) {
+	if s.Kind != snapshot.Full {
+		return nil, snapshot.ErrNotFull
+	}
+	c := NewCatalog(s.ChainID)
+	c.Checkpoint = checkpoint
+	for _, pair := range s.Pairs() {
+		c.Add(pair)
+	}
+	return c, nil
+}
+
+// Snapshot returns a full snapshot of the pairs at the block
+func (c *Catalog) Snapshot(block uint64) *snapshot.Snapshot {
+	return snapshot.New(c.ChainID, block, c.Pairs()...)
+}
+
+// Add adds the pair and its tokens, or replaces the pair of the same address
+func (c *Catalog) Add(pair *entities.Pair) {
+	if _, ok := c.pairs[pair.Address]; !ok {
+		c.order = append(c.order, pair.Address)
+	}
+	c.pairs[pair.Address] = pair
+	c.Tokens[pair.Token0().Address] = pair.Token0()
+	c.Tokens[pair.Token1().Address] = pair.Token1()
+	delete(c.Skipped, pair.Address)
+}
+
+// Pair returns the pair of the address, or nil
+func (c *Catalog) Pair(address common.Address) *entities.Pair {
+	return c.pairs[address]
+}
+
+// Pairs returns the pairs in the order they were discovered
+func (c *Catalog) Pairs() []*entities.Pair {
+	pairs := make([]*entities.Pair, len(c.order))
+	for i, address := range c.order {
+		pairs[i] = c.pairs[address]
+	}
+	return pairs
+}
+
+// Discoverer fills catalogs from the sources it is given
+type Discoverer struct {
+	Factory FactorySource // Needed by Enumerate
+	Logs    LogSource     // Needed by Scan
+	Pairs   PairSource
+	Tokens  TokenSource
+	// Factory and init code hash of the pairs, the discovered address is kept. Defaults to the Uniswap V2 ones.
+	Options    *entities.PairOptions
+	BlockRange uint64 // The most blocks per LogSource query. Defaults to DefaultBlockRange.
+	// Record pairs whose tokens or reserves cannot be read in Catalog.Skipped and go on, instead of stopping
+	SkipInvalid bool
+}
+
+// Enumerate adds the pairs of the factory from the checkpoint index up to allPairsLength.
+// The checkpoint advances with every pair, so an interrupted enumeration resumes where it stopped.
+func (d *Discoverer) Enumerate(ctx context.Context, c *Catalog) error {
+	if d.Factory == nil {
+		return ErrNoSource
+	}
+	length, err := d.Factory.AllPairsLength(ctx)
+	if err != nil {
+		return err
+	}
+	for index := c.Checkpoint.NextIndex; index < length; index++ {
+		address, err := d.Factory.AllPairs(ctx, index)
+		if err != nil {
+			return err
+		}
+		if c.pairs[address] == nil {
+			token0, token1, err := d.pairTokens(ctx, address)
+			if err == nil {
+				err = d.resolve(ctx, c, address, token0, token1)
+			}
+			if err != nil {
+				return fmt.Errorf("pair %d %s: %w", index, address.Hex(), err)
+			}
+		}
+		c.Checkpoint.NextIndex = index + 1
+	}
+	return nil
+}
+
+func (d *Discoverer) pairTokens(ctx context.Context, address common.Address) (common.Address, common.Address, error) {
+	if d.Pairs == nil {
+		return common.Address{}, common.Address{}, ErrNoSource
+	}
+	return d.Pairs.PairTokens(ctx, address)
+}
+
+// Scan adds the pairs created from the checkpoint block up to toBlock, inclusive.
+// The checkpoint advances with every block range queried.
+func (d *Discoverer) Scan(ctx context.Context, c *Catalog, toBlock uint64) error {
+	if d.Logs == nil {
+		return ErrNoSource
+	}
+	blockRange := d.BlockRange
+	if blockRange == 0 {
+		blockRange = DefaultBlockRange
+	}
+	for from := c.Checkpoint.NextBlock; from <= toBlock; {
+		to := toBlock
+		if toBlock-from >= blockRange {
+			to = from + blockRange - 1
+		}
+		created, err := d.Logs.PairCreated(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		for _, event := range created {
+			if c.pairs[event.Pair] != nil {
+				continue
+			}
+			if err := d.resolve(ctx, c, event.Pair, event.Token0, event.Token1); err != nil {
+				return fmt.Errorf("pair %d %s: %w", event.Index, event.Pair.Hex(), err)
+			}
+		}
+		c.Checkpoint.NextBlock = to + 1
+		from = to + 1
+	}
+	return nil
+}
+
+// Refresh reads the reserves of every pair of the catalog again
+func (d *Discoverer) Refresh(ctx context.Context, c *Catalog) error {
+	if d.Pairs == nil {
+		return ErrNoSource
+	}
+	for _, pair := range c.Pairs() {
+		reserve0, reserve1, err := d.Pairs.Reserves(ctx, pair.Address)
+		if err != nil {
+			return fmt.Errorf("pair %s: %w", pair.Address.Hex(), err)
+		}
+		next, err := entities.NewPair(core.FromRawAmount(pair.Token0(), reserve0), core.FromRawAmount(pair.Token1(), reserve1), pair.Options)
+		if err != nil {
+			return err
+		}
+		c.Add(next)
+	}
+	return nil
+}
+
+// resolve adds the pair with its tokens and reserves, or skips it if SkipInvalid is set
+func (d *Discoverer) resolve(ctx context.Context, c *Catalog, address, token0, token1 common.Address) error {
+	pair, err := d.pair(ctx, c, address, token0, token1)
+	if err != nil {
+		if d.SkipInvalid && !errors.Is(err, ErrNoSource) && ctx.Err() == nil {
+			c.Skipped[address] = err
+			return nil
+		}
+		return err
+	}
+	c.Add(pair)
+	return nil
+}
+
+func (d *Discoverer) pair(ctx context.Context, c *Catalog, address, address0, address1 common.Address) (*entities.Pair, error) {
+	if d.Pairs == nil {
+		return nil, ErrNoSource
+	}
+	token0, err := d.token(ctx, c, address0)
+	if err != nil {
+		return nil, err
+	}
+	token1, err := d.token(ctx, c, address1)
+	if err != nil {
+		return nil, err
+	}
+	reserve0, reserve1, err := d.Pairs.Reserves(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	options := &entities.PairOptions{Factory: entities.FactoryAddress, InitCodeHash: entities.InitCodeHash, Address: &address}
+	if d.Options != nil {
+		options.Factory, options.InitCodeHash = d.Options.Factory, d.Options.InitCodeHash
+	}
+	return entities.NewPair(core.FromRawAmount(token0, reserve0), core.FromRawAmount(token1, reserve1), options)
+}
+
+func (d *Discoverer) token(ctx context.Context, c *Catalog, address common.Address) (*core.Token, error) {
+	if token := c.Tokens[address]; token != nil {
+		return token, nil
+	}
+	if d.Tokens == nil {
+		return nil, ErrNoSource
+	}
+	metadata, err := d.Tokens.TokenMetadata(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("token %s: %w", address.Hex(), err)
+	}
+	if metadata.Decimals >= 255 {
+		return nil, fmt.Errorf("token %s: %w", address.Hex(), ErrInvalidToken)
+	}
+	token := core.NewToken(c.ChainID, address, uint(metadata.Decimals), metadata.Symbol, metadata.Name)
+	c.Tokens[address] = token
+	return token, nil
+}
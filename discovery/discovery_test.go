@@ -0,0 +1,231 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/discovery"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var errUnreadable = errors.New("unreadable")
+
+type stubPair struct {
+	address            common.Address
+	token0, token1     common.Address
+	reserve0, reserve1 int64
+	block              uint64
+}
+
+// stubChain is an offline factory with its pairs and tokens
+type stubChain struct {
+	pairs    []stubPair
+	tokens   map[common.Address]*discovery.TokenMetadata
+	failAt   int // AllPairs fails once at this index, if not negative
+	queries  [][2]uint64
+	metadata int // TokenMetadata calls
+}
+
+func address(n int64) common.Address {
+	return common.BigToAddress(big.NewInt(n))
+}
+
+func newStubChain() *stubChain {
+	return &stubChain{
+		pairs: []stubPair{
+			{address(0xa1), address(1), address(2), 1000, 2000, 10},
+			{address(0xa2), address(2), address(3), 3000, 3000, 10},
+			{address(0xa3), address(1), address(4), 100, 100, 7000},
+			{address(0xa4), address(1), address(3), 5000, 1000, 12000},
+		},
+		tokens: map[common.Address]*discovery.TokenMetadata{
+			address(1): {Decimals: 18, Symbol: "t1", Name: "Token 1"},
+			address(2): {Decimals: 6, Symbol: "t2", Name: "Token 2"},
+			address(3): {Decimals: 8, Symbol: "t3", Name: "Token 3"},
+		},
+		failAt: -1,
+	}
+}
+
+func (s *stubChain) AllPairsLength(context.Context) (uint64, error) {
+	return uint64(len(s.pairs)), nil
+}
+
+func (s *stubChain) AllPairs(_ context.Context, index uint64) (common.Address, error) {
+	if int(index) == s.failAt {
+		s.failAt = -1
+		return common.Address{}, errUnreadable
+	}
+	return s.pairs[index].address, nil
+}
+
+func (s *stubChain) PairCreated(_ context.Context, fromBlock, toBlock uint64) ([]*discovery.PairCreated, error) {
+	s.queries = append(s.queries, [2]uint64{fromBlock, toBlock})
+	var created []*discovery.PairCreated
+	for i, p := range s.pairs {
+		if p.block >= fromBlock && p.block <= toBlock {
+			created = append(created, &discovery.PairCreated{Token0: p.token0, Token1: p.token1, Pair: p.address, Index: uint64(i), Block: p.block})
+		}
+	}
+	return created, nil
+}
+
+func (s *stubChain) find(address common.Address) *stubPair {
+	for i := range s.pairs {
+		if s.pairs[i].address == address {
+			return &s.pairs[i]
+		}
+	}
+	return nil
+}
+
+func (s *stubChain) PairTokens(_ context.Context, address common.Address) (common.Address, common.Address, error) {
+	p := s.find(address)
+	return p.token0, p.token1, nil
+}
+
+func (s *stubChain) Reserves(_ context.Context, address common.Address) (*big.Int, *big.Int, error) {
+	p := s.find(address)
+	return big.NewInt(p.reserve0), big.NewInt(p.reserve1), nil
+}
+
+func (s *stubChain) TokenMetadata(_ context.Context, token common.Address) (*discovery.TokenMetadata, error) {
+	s.metadata++
+	if metadata, ok := s.tokens[token]; ok {
+		return metadata, nil
+	}
+	return nil, errUnreadable
+}
+
+func discoverer(chain *stubChain) *discovery.Discoverer {
+	return &discovery.Discoverer{Factory: chain, Logs: chain, Pairs: chain, Tokens: chain, BlockRange: 5000}
+}
+
+func TestEnumerate(t *testing.T) {
+	ctx := context.Background()
+	chain := newStubChain()
+	chain.failAt = 1
+	d := discoverer(chain)
+	catalog := discovery.NewCatalog(1)
+
+	if err := d.Enumerate(ctx, catalog); !errors.Is(err, errUnreadable) {
+		t.Fatalf("expect[%v], but got[%v]", errUnreadable, err)
+	}
+	if catalog.Checkpoint.NextIndex != 1 || len(catalog.Pairs()) != 1 {
+		t.Errorf("expect[1 pair], but got[%d at %d]", len(catalog.Pairs()), catalog.Checkpoint.NextIndex)
+	}
+
+	// resumes at index 1 and stops at the pair of the token without metadata
+	if err := d.Enumerate(ctx, catalog); !errors.Is(err, errUnreadable) {
+		t.Fatalf("expect[%v], but got[%v]", errUnreadable, err)
+	}
+	if catalog.Checkpoint.NextIndex != 2 || len(catalog.Pairs()) != 2 {
+		t.Errorf("expect[2 pairs], but got[%d at %d]", len(catalog.Pairs()), catalog.Checkpoint.NextIndex)
+	}
+
+	d.SkipInvalid = true
+	if err := d.Enumerate(ctx, catalog); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Checkpoint.NextIndex != 4 || len(catalog.Pairs()) != 3 || !errors.Is(catalog.Skipped[address(0xa3)], errUnreadable) {
+		t.Errorf("expect[3 pairs, 1 skipped], but got[%d at %d, %v]", len(catalog.Pairs()), catalog.Checkpoint.NextIndex, catalog.Skipped)
+	}
+	// token metadata is read once per token, and again for the unreadable one
+	if chain.metadata != 5 || len(catalog.Tokens) != 3 {
+		t.Errorf("expect[5 metadata reads of 3 tokens], but got[%d of %d]", chain.metadata, len(catalog.Tokens))
+	}
+	pair := catalog.Pair(address(0xa1))
+	if pair.Address != address(0xa1) || pair.Token1().Symbol() != "t2" || pair.Token1().Decimals() != 6 || pair.Reserve1().Quotient().Int64() != 2000 {
+		t.Errorf("expect[pair 0xa1 of t2], but got[%+v]", pair)
+	}
+
+	// the catalog routes trades
+	trades, err := entities.BestTradeExactIn(catalog.Pairs(), core.FromRawAmount(catalog.Tokens[address(2)], big.NewInt(100)),
+		catalog.Tokens[address(3)], nil, nil, nil, nil)
+	if err != nil || len(trades) == 0 {
+		t.Fatalf("expect[trades], but got[%v]", err)
+	}
+	if len(trades[0].Route.Pairs) != 1 || trades[0].Route.Pairs[0].Address != address(0xa2) {
+		t.Errorf("expect[direct route], but got[%+v]", trades[0].Route.Pairs)
+	}
+
+	// the reserves change
+	chain.pairs[0].reserve0 = 1500
+	if err := d.Refresh(ctx, catalog); err != nil {
+		t.Fatal(err)
+	}
+	if reserve := catalog.Pair(address(0xa1)).Reserve0().Quotient().Int64(); reserve != 1500 {
+		t.Errorf("expect[1500], but got[%d]", reserve)
+	}
+
+	// a catalog saved as a snapshot resumes
+	resumed, err := discovery.FromSnapshot(catalog.Snapshot(20000), catalog.Checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.pairs = append(chain.pairs, stubPair{address(0xa5), address(2), address(4), 1, 1, 20000})
+	chain.tokens[address(4)] = &discovery.TokenMetadata{Decimals: 0, Symbol: "t4"}
+	if err := d.Enumerate(ctx, resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Checkpoint.NextIndex != 5 || len(resumed.Pairs()) != 4 || resumed.Pair(address(0xa3)) != nil {
+		t.Errorf("expect[4 pairs], but got[%d at %d]", len(resumed.Pairs()), resumed.Checkpoint.NextIndex)
+	}
+
+	if err := (&discovery.Discoverer{}).Enumerate(ctx, catalog); !errors.Is(err, discovery.ErrNoSource) {
+		t.Errorf("expect[%v], but got[%v]", discovery.ErrNoSource, err)
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	ctx := context.Background()
+	chain := newStubChain()
+	chain.tokens[address(4)] = &discovery.TokenMetadata{Decimals: 255, Symbol: "t4"}
+	d := discoverer(chain)
+	catalog := discovery.NewCatalog(1)
+
+	if err := d.Enumerate(ctx, catalog); !errors.Is(err, discovery.ErrInvalidToken) {
+		t.Fatalf("expect[%v], but got[%v]", discovery.ErrInvalidToken, err)
+	}
+	d.SkipInvalid = true
+	if err := d.Enumerate(ctx, catalog); err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Pairs()) != 3 || !errors.Is(catalog.Skipped[address(0xa3)], discovery.ErrInvalidToken) {
+		t.Errorf("expect[3 pairs, 0xa3 skipped], but got[%d, %v]", len(catalog.Pairs()), catalog.Skipped)
+	}
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	chain := newStubChain()
+	chain.tokens[address(4)] = &discovery.TokenMetadata{Decimals: 18, Symbol: "t4"}
+	d := discoverer(chain)
+	catalog := discovery.NewCatalog(1)
+
+	if err := d.Scan(ctx, catalog, 9999); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Checkpoint.NextBlock != 10000 || len(catalog.Pairs()) != 3 {
+		t.Errorf("expect[3 pairs], but got[%d at %d]", len(catalog.Pairs()), catalog.Checkpoint.NextBlock)
+	}
+	if err := d.Scan(ctx, catalog, 12000); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Checkpoint.NextBlock != 12001 || len(catalog.Pairs()) != 4 {
+		t.Errorf("expect[4 pairs], but got[%d at %d]", len(catalog.Pairs()), catalog.Checkpoint.NextBlock)
+	}
+	expect := [][2]uint64{{0, 4999}, {5000, 9999}, {10000, 12000}}
+	if len(chain.queries) != len(expect) {
+		t.Fatalf("expect[%v], but got[%v]", expect, chain.queries)
+	}
+	for i := range expect {
+		if chain.queries[i] != expect[i] {
+			t.Errorf("expect[%v], but got[%v]", expect, chain.queries)
+		}
+	}
+}
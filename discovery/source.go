@@ -0,0 +1,224 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	FactoryABI = "[ { \"anonymous\": false, \"inputs\": [ { \"indexed\": true, \"internalType\": \"address\", \"name\": \"token0\", \"type\": \"address\" }, { \"indexed\": true, \"internalType\": \"address\", \"name\": \"token1\", \"type\": \"address\" }, { \"indexed\": false, \"internalType\": \"address\", \"name\": \"pair\", \"type\": \"address\" }, { \"indexed\": false, \"internalType\": \"uint256\", \"name\": \"\", \"type\": \"uint256\" } ], \"name\": \"PairCreated\", \"type\": \"event\" }, { \"inputs\": [ { \"internalType\": \"uint256\", \"name\": \"\", \"type\": \"uint256\" } ], \"name\": \"allPairs\", \"outputs\": [ { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"allPairsLength\", \"outputs\": [ { \"internalType\": \"uint256\", \"name\": \"\", \"type\": \"uint256\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+	PairABI    = "[ { \"inputs\": [], \"name\": \"token0\", \"outputs\": [ { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"token1\", \"outputs\": [ { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"getReserves\", \"outputs\": [ { \"internalType\": \"uint112\", \"name\": \"reserve0\", \"type\": \"uint112\" }, { \"internalType\": \"uint112\", \"name\": \"reserve1\", \"type\": \"uint112\" }, { \"internalType\": \"uint32\", \"name\": \"blockTimestampLast\", \"type\": \"uint32\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+	// TokenMetadataABI is the optional ERC20 metadata, some tokens return bytes32 instead of string
+	TokenMetadataABI = "[ { \"inputs\": [], \"name\": \"decimals\", \"outputs\": [ { \"internalType\": \"uint8\", \"name\": \"\", \"type\": \"uint8\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"symbol\", \"outputs\": [ { \"internalType\": \"string\", \"name\": \"\", \"type\": \"string\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"name\", \"outputs\": [ { \"internalType\": \"string\", \"name\": \"\", \"type\": \"string\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+)
+
+// PairCreatedTopic is the topic of PairCreated(address indexed token0, address indexed token1, address pair, uint)
+var PairCreatedTopic = crypto.Keccak256Hash([]byte("PairCreated(address,address,address,uint256)"))
+
+var (
+	ErrNoFilterer = errors.New("chain source has no log filterer")
+	ErrInvalidLog = errors.New("invalid PairCreated log")
+)
+
+// PairCreated is a pair creation of a factory
+type PairCreated struct {
+	Token0 common.Address
+	Token1 common.Address
+	Pair   common.Address
+	Index  uint64 // The index of the pair in allPairs
+	Block  uint64
+}
+
+// TokenMetadata of an ERC20 token
+type TokenMetadata struct {
+	Decimals uint8
+	Symbol   string
+	Name     string
+}
+
+// FactorySource enumerates the pairs of a factory by index
+type FactorySource interface {
+	AllPairsLength(ctx context.Context) (uint64, error)
+	AllPairs(ctx context.Context, index uint64) (common.Address, error)
+}
+
+// LogSource returns the pair creations of a factory in the inclusive block range, ordered by block
+type LogSource interface {
+	PairCreated(ctx context.Context, fromBlock, toBlock uint64) ([]*PairCreated, error)
+}
+
+// PairSource reads the state of pairs
+type PairSource interface {
+	PairTokens(ctx context.Context, pair common.Address) (token0, token1 common.Address, err error)
+	Reserves(ctx context.Context, pair common.Address) (reserve0, reserve1 *big.Int, err error)
+}
+
+// TokenSource reads the metadata of tokens
+type TokenSource interface {
+	TokenMetadata(ctx context.Context, token common.Address) (*TokenMetadata, error)
+}
+
+// ChainSource reads a factory, its pairs and their tokens through a node, e.g. an ethclient.Client.
+// It is all of FactorySource, LogSource, PairSource and TokenSource.
+type ChainSource struct {
+	Caller   bind.ContractCaller
+	Filterer ethereum.LogFilterer // Needed for PairCreated only
+	Factory  common.Address
+	Block    *big.Int // The block state is read at, nil for the latest
+}
+
+func (s *ChainSource) call(ctx context.Context, to common.Address, abiJSON, method string, args ...interface{}) ([]interface{}, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.Caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, s.Block)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsed.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("%s of %s: %w", method, to.Hex(), err)
+	}
+	return values, nil
+}
+
+// AllPairsLength returns the number of pairs of the factory
+func (s *ChainSource) AllPairsLength(ctx context.Context) (uint64, error) {
+	values, err := s.call(ctx, s.Factory, FactoryABI, "allPairsLength")
+	if err != nil {
+		return 0, err
+	}
+	return values[0].(*big.Int).Uint64(), nil
+}
+
+// AllPairs returns the pair of the index
+func (s *ChainSource) AllPairs(ctx context.Context, index uint64) (common.Address, error) {
+	values, err := s.call(ctx, s.Factory, FactoryABI, "allPairs", new(big.Int).SetUint64(index))
+	if err != nil {
+		return common.Address{}, err
+	}
+	return values[0].(common.Address), nil
+}
+
+// PairCreated returns the pair creations of the factory in the inclusive block range
+func (s *ChainSource) PairCreated(ctx context.Context, fromBlock, toBlock uint64) ([]*PairCreated, error) {
+	if s.Filterer == nil {
+		return nil, ErrNoFilterer
+	}
+	logs, err := s.Filterer.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{s.Factory},
+		Topics:    [][]common.Hash{{PairCreatedTopic}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*PairCreated, 0, len(logs))
+	for i := range logs {
+		event, err := UnpackPairCreated(&logs[i])
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, event)
+	}
+	return created, nil
+}
+
+// UnpackPairCreated decodes a PairCreated log
+func UnpackPairCreated(log *types.Log) (*PairCreated, error) {
+	if len(log.Topics) != 3 || log.Topics[0] != PairCreatedTopic || len(log.Data) != 64 {
+		return nil, fmt.Errorf("%w: tx %s index %d", ErrInvalidLog, log.TxHash.Hex(), log.Index)
+	}
+	length := new(big.Int).SetBytes(log.Data[32:])
+	if length.Sign() == 0 || !length.IsUint64() {
+		return nil, fmt.Errorf("%w: tx %s index %d", ErrInvalidLog, log.TxHash.Hex(), log.Index)
+	}
+	return &PairCreated{
+		Token0: common.BytesToAddress(log.Topics[1].Bytes()),
+		Token1: common.BytesToAddress(log.Topics[2].Bytes()),
+		Pair:   common.BytesToAddress(log.Data[:32]),
+		Index:  length.Uint64() - 1,
+		Block:  log.BlockNumber,
+	}, nil
+}
+
+// PairTokens returns the tokens of the pair
+func (s *ChainSource) PairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error) {
+	token0, err := s.call(ctx, pair, PairABI, "token0")
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	token1, err := s.call(ctx, pair, PairABI, "token1")
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	return token0[0].(common.Address), token1[0].(common.Address), nil
+}
+
+// Reserves returns the reserves of the pair
+func (s *ChainSource) Reserves(ctx context.Context, pair common.Address) (*big.Int, *big.Int, error) {
+	values, err := s.call(ctx, pair, PairABI, "getReserves")
+	if err != nil {
+		return nil, nil, err
+	}
+	return values[0].(*big.Int), values[1].(*big.Int), nil
+}
+
+// TokenMetadata returns the decimals, symbol and name of the token. Symbols and names returned as bytes32 are
+// decoded too. Only the decimals are required: the symbol or name of a token failing to return them is empty.
+func (s *ChainSource) TokenMetadata(ctx context.Context, token common.Address) (*TokenMetadata, error) {
+	values, err := s.call(ctx, token, TokenMetadataABI, "decimals")
+	if err != nil {
+		return nil, err
+	}
+	metadata := &TokenMetadata{Decimals: values[0].(uint8)}
+	// a token without the optional symbol or name keeps them empty, unless the context ended the call
+	if metadata.Symbol, err = s.text(ctx, token, "symbol"); err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if metadata.Name, err = s.text(ctx, token, "name"); err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return metadata, nil
+}
+
+// text reads a string or bytes32 metadata field
+func (s *ChainSource) text(ctx context.Context, token common.Address, method string) (string, error) {
+	parsed, err := abi.JSON(strings.NewReader(TokenMetadataABI))
+	if err != nil {
+		return "", err
+	}
+	data, err := parsed.Pack(method)
+	if err != nil {
+		return "", err
+	}
+	result, err := s.Caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, s.Block)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 32 {
+		return string(bytes.TrimRight(result, "\x00")), nil
+	}
+	values, err := parsed.Unpack(method, result)
+	if err != nil {
+		return "", fmt.Errorf("%s of %s: %w", method, token.Hex(), err)
+	}
+	return values[0].(string), nil
+}
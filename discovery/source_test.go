@@ -0,0 +1,138 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vaulverin/uniswapv2-sdk/discovery"
+)
+
+// stubCaller answers calls with results by contract and selector
+type stubCaller struct {
+	t       *testing.T
+	results map[common.Address]map[string][]byte
+	logs    []types.Log
+}
+
+func (s *stubCaller) set(to common.Address, abiJSON, method string, values ...interface{}) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	result, err := parsed.Methods[method].Outputs.Pack(values...)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.setRaw(to, parsed.Methods[method].ID, result)
+}
+
+func (s *stubCaller) setRaw(to common.Address, selector, result []byte) {
+	if s.results[to] == nil {
+		s.results[to] = map[string][]byte{}
+	}
+	s.results[to][string(selector)] = result
+}
+
+func (s *stubCaller) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (s *stubCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	result, ok := s.results[*call.To][string(call.Data[:4])]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return result, nil
+}
+
+func (s *stubCaller) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, log := range s.logs {
+		if log.BlockNumber >= query.FromBlock.Uint64() && log.BlockNumber <= query.ToBlock.Uint64() {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (s *stubCaller) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func pairCreatedLog(token0, token1, pair common.Address, length int64, block uint64) types.Log {
+	return types.Log{
+		Topics:      []common.Hash{discovery.PairCreatedTopic, common.BytesToHash(token0.Bytes()), common.BytesToHash(token1.Bytes())},
+		Data:        append(common.LeftPadBytes(pair.Bytes(), 32), common.LeftPadBytes(big.NewInt(length).Bytes(), 32)...),
+		BlockNumber: block,
+	}
+}
+
+func TestChainSource(t *testing.T) {
+	ctx := context.Background()
+	factory := address(0xf0)
+	caller := &stubCaller{t: t, results: map[common.Address]map[string][]byte{}}
+	caller.set(factory, discovery.FactoryABI, "allPairsLength", big.NewInt(1))
+	caller.set(factory, discovery.FactoryABI, "allPairs", address(0xa1))
+	caller.set(address(0xa1), discovery.PairABI, "token0", address(1))
+	caller.set(address(0xa1), discovery.PairABI, "token1", address(2))
+	caller.set(address(0xa1), discovery.PairABI, "getReserves", big.NewInt(1000), big.NewInt(2000), uint32(0))
+	caller.set(address(1), discovery.TokenMetadataABI, "decimals", uint8(18))
+	caller.set(address(1), discovery.TokenMetadataABI, "symbol", "t1")
+	caller.set(address(1), discovery.TokenMetadataABI, "name", "Token 1")
+	// a token returning bytes32 metadata, like MKR
+	parsed, _ := abi.JSON(strings.NewReader(discovery.TokenMetadataABI))
+	caller.set(address(2), discovery.TokenMetadataABI, "decimals", uint8(6))
+	caller.setRaw(address(2), parsed.Methods["symbol"].ID, common.RightPadBytes([]byte("MKR"), 32))
+	caller.setRaw(address(2), parsed.Methods["name"].ID, common.RightPadBytes([]byte("Maker"), 32))
+	caller.logs = []types.Log{pairCreatedLog(address(1), address(2), address(0xa1), 1, 50)}
+
+	source := &discovery.ChainSource{Caller: caller, Filterer: caller, Factory: factory}
+	d := &discovery.Discoverer{Factory: source, Logs: source, Pairs: source, Tokens: source}
+	catalog := discovery.NewCatalog(1)
+	if err := d.Enumerate(ctx, catalog); err != nil {
+		t.Fatal(err)
+	}
+	pair := catalog.Pair(address(0xa1))
+	if pair == nil || pair.Token0().Symbol() != "t1" || pair.Token1().Symbol() != "MKR" || pair.Token1().Name() != "Maker" ||
+		pair.Token1().Decimals() != 6 || pair.Reserve1().Quotient().Int64() != 2000 {
+		t.Fatalf("expect[t1/MKR pair], but got[%+v]", pair)
+	}
+
+	created, err := source.PairCreated(ctx, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || *created[0] != (discovery.PairCreated{Token0: address(1), Token1: address(2), Pair: address(0xa1), Index: 0, Block: 50}) {
+		t.Errorf("expect[pair 0xa1 at index 0], but got[%+v]", created)
+	}
+
+	bad := pairCreatedLog(address(1), address(2), address(0xa1), 0, 50)
+	if _, err := discovery.UnpackPairCreated(&bad); !errors.Is(err, discovery.ErrInvalidLog) {
+		t.Errorf("expect[%v], but got[%v]", discovery.ErrInvalidLog, err)
+	}
+	if _, err := (&discovery.ChainSource{Caller: caller}).PairCreated(ctx, 0, 100); !errors.Is(err, discovery.ErrNoFilterer) {
+		t.Errorf("expect[%v], but got[%v]", discovery.ErrNoFilterer, err)
+	}
+	if _, err := source.TokenMetadata(ctx, address(3)); err == nil {
+		t.Errorf("expect[error], but got[nil]")
+	}
+
+	// a token without symbol nor name only needs decimals
+	caller.set(address(4), discovery.TokenMetadataABI, "decimals", uint8(8))
+	metadata, err := source.TokenMetadata(ctx, address(4))
+	if err != nil || *metadata != (discovery.TokenMetadata{Decimals: 8}) {
+		t.Errorf("expect[8 decimals without symbol nor name], but got[%+v, %v]", metadata, err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := source.TokenMetadata(cancelled, address(4)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect[%v], but got[%v]", context.Canceled, err)
+	}
+}
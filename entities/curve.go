@@ -0,0 +1,210 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+var (
+	ErrUnreachablePrice   = errors.New("price is not reached by any input amount")
+	ErrInvalidPriceImpact = errors.New("price impact must be at least 0 and below 1")
+)
+
+// Curve is the output of a route as a function of its input, out = A·x / (B + C·x), composed of the pair curves
+// out = 997·reserveOut·x / (1000·reserveIn + 997·x) of its hops.
+//
+// The curve is exact on rationals, while the pairs floor the amount of every hop. For exact input GetAmountOut is
+// never below what the route yields, and at most OutputRounding above. For exact output GetAmountIn is never above
+// what the route needs, and at most InputRounding below. Both are exact for a single hop.
+type Curve struct {
+	Input  core.Currency
+	Output core.Currency
+	A      *big.Int
+	B      *big.Int
+	C      *big.Int
+
+	hops           [][3]*big.Int // a, b and c of every hop
+	midPrice       *core.Price
+	outputRounding *big.Int
+}
+
+// Curve returns the composed curve of the route. The route caches the curve and returns a copy of it on every call.
+// Returns ErrInsufficientReserves if a reserve of the route is empty.
+func (r *Route) Curve() (*Curve, error) {
+	if r.curve != nil {
+		return r.curve.copy(), nil
+	}
+	midPrice, err := r.MidPrice()
+	if err != nil {
+		return nil, err
+	}
+	c := &Curve{Input: r.Input, Output: r.Output, midPrice: midPrice}
+	for i, pair := range r.Pairs {
+		reserveIn, reserveOut := pair.Reserve0().Quotient(), pair.Reserve1().Quotient()
+		if !r.Path[i].Equal(pair.Token0()) {
+			reserveIn, reserveOut = reserveOut, reserveIn
+		}
+		if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+			return nil, ErrInsufficientReserves
+		}
+		hop := [3]*big.Int{new(big.Int).Mul(B997, reserveOut), new(big.Int).Mul(B1000, reserveIn), B997}
+		c.hops = append(c.hops, hop)
+		if i == 0 {
+			c.A, c.B, c.C = new(big.Int).Set(hop[0]), new(big.Int).Set(hop[1]), new(big.Int).Set(hop[2])
+			continue
+		}
+		// out = a·y / (b + c·y) of y = A·x / (B + C·x)
+		cNext := new(big.Int).Mul(hop[1], c.C)
+		cNext.Add(cNext, new(big.Int).Mul(hop[2], c.A))
+		c.A, c.B, c.C = new(big.Int).Mul(hop[0], c.A), new(big.Int).Mul(hop[1], c.B), cNext
+	}
+	c.outputRounding = c.roundingOut()
+	r.curve = c
+	return c.copy(), nil
+}
+
+// copy returns the curve with its own A, B and C, the hops and the rounding are never modified
+func (c *Curve) copy() *Curve {
+	curve := *c
+	curve.A, curve.B, curve.C = new(big.Int).Set(c.A), new(big.Int).Set(c.B), new(big.Int).Set(c.C)
+	return &curve
+}
+
+// roundingOut bounds the output lost to the flooring of the hops: the amount after hop i is less than 1 below the
+// curve, which the later hops scale by at most their rate at zero size, the product of a/b
+func (c *Curve) roundingOut() *big.Int {
+	sum, rate := new(big.Rat), big.NewRat(1, 1)
+	for i := len(c.hops) - 1; i > 0; i-- {
+		rate.Mul(rate, new(big.Rat).SetFrac(c.hops[i][0], c.hops[i][1]))
+		sum.Add(sum, rate)
+	}
+	return ceilRat(sum)
+}
+
+// ceilRat returns the smallest integer not below the non-negative rational
+func ceilRat(r *big.Rat) *big.Int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, One)
+	}
+	return q
+}
+
+// GetAmountOut returns floor(A·x / (B + C·x)) of the input amount x.
+// Returns ErrInsufficientInputAmount if the input is not positive or the output is zero.
+func (c *Curve) GetAmountOut(amountIn *big.Int) (*big.Int, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, ErrInsufficientInputAmount
+	}
+	denominator := new(big.Int).Mul(c.C, amountIn)
+	denominator.Add(denominator, c.B)
+	amountOut := new(big.Int).Mul(c.A, amountIn)
+	amountOut.Quo(amountOut, denominator)
+	if amountOut.Sign() == 0 {
+		return nil, ErrInsufficientInputAmount
+	}
+	return amountOut, nil
+}
+
+// OutputRounding returns how much the output of the route may be below GetAmountOut, for any input
+func (c *Curve) OutputRounding() *big.Int {
+	return new(big.Int).Set(c.outputRounding)
+}
+
+// GetAmountIn returns floor(B·y / (A - C·y)) + 1 of the output amount y, as getAmountIn rounds.
+// Returns ErrInsufficientReserves if the output is not below A/C, the most the route can yield.
+func (c *Curve) GetAmountIn(amountOut *big.Int) (*big.Int, error) {
+	denominator := new(big.Int).Mul(c.C, amountOut)
+	denominator.Sub(c.A, denominator)
+	if amountOut.Sign() < 0 || denominator.Sign() <= 0 {
+		return nil, ErrInsufficientReserves
+	}
+	amountIn := new(big.Int).Mul(c.B, amountOut)
+	amountIn.Quo(amountIn, denominator)
+	return amountIn.Add(amountIn, One), nil
+}
+
+// InputRounding returns how much the input the route needs for the output amount may be above GetAmountIn.
+// Returns ErrInsufficientReserves if the rounded amounts exceed what a hop can yield.
+func (c *Curve) InputRounding(amountOut *big.Int) (*big.Int, error) {
+	// v is the exact amount after a hop and v+e the most the hops from there on may ask for
+	v, e := new(big.Rat).SetInt(amountOut), new(big.Rat)
+	for i := len(c.hops) - 1; i >= 0; i-- {
+		a, b, cc := new(big.Rat).SetInt(c.hops[i][0]), new(big.Rat).SetInt(c.hops[i][1]), new(big.Rat).SetInt(c.hops[i][2])
+		g := func(y *big.Rat) (*big.Rat, error) {
+			denominator := new(big.Rat).Sub(a, new(big.Rat).Mul(cc, y))
+			if denominator.Sign() <= 0 {
+				return nil, ErrInsufficientReserves
+			}
+			return new(big.Rat).Quo(new(big.Rat).Mul(b, y), denominator), nil
+		}
+		exact, err := g(v)
+		if err != nil {
+			return nil, err
+		}
+		most, err := g(new(big.Rat).Add(v, e))
+		if err != nil {
+			return nil, err
+		}
+		e.Sub(most.Add(most, big.NewRat(1, 1)), exact)
+		v = exact
+	}
+	// the route needs less than GetAmountIn + e, an integer
+	rounding := ceilRat(e)
+	return rounding.Sub(rounding, One), nil
+}
+
+// MarginalPrice returns the price of the next unit of input after the input amount x, A·B / (B + C·x)^2.
+// At zero size it is the mid price of the route net of the fees.
+func (c *Curve) MarginalPrice(amountIn *big.Int) *core.Price {
+	denominator := new(big.Int).Mul(c.C, amountIn)
+	denominator.Add(denominator, c.B)
+	denominator.Mul(denominator, denominator)
+	return core.NewPrice(c.Input, c.Output, denominator, new(big.Int).Mul(c.A, c.B))
+}
+
+// AmountInForPrice returns the largest input amount after which the marginal price is not below the price.
+// Returns ErrUnreachablePrice if the price is above the marginal price at zero size.
+func (c *Curve) AmountInForPrice(price *core.Price) (*big.Int, error) {
+	if !price.BaseCurrency.Equal(c.Input) || !price.QuoteCurrency.Equal(c.Output) {
+		return nil, core.ErrDifferentCurrencies
+	}
+	if price.Numerator.Sign() <= 0 || price.Denominator.Sign() <= 0 {
+		return nil, ErrUnreachablePrice
+	}
+	// (B + C·x)^2 <= A·B·denominator / numerator
+	square := new(big.Int).Mul(c.A, c.B)
+	square.Mul(square, price.Denominator)
+	square.Quo(square, price.Numerator)
+	root := new(big.Int).Sqrt(square)
+	if root.Cmp(c.B) < 0 {
+		return nil, ErrUnreachablePrice
+	}
+	root.Sub(root, c.B)
+	return root.Quo(root, c.C), nil
+}
+
+// AmountInForPriceImpact returns the largest input amount whose price impact on the curve is not above the price
+// impact. As the curve is not below the output of the route, the impact of a trade of the amount may be slightly
+// higher. Returns ErrUnreachablePrice if the fees alone exceed the price impact.
+func (c *Curve) AmountInForPriceImpact(priceImpact *core.Percent) (*big.Int, error) {
+	if priceImpact.LessThan(ZeroFraction) || !priceImpact.LessThan(core.NewFraction(One, One)) {
+		return nil, ErrInvalidPriceImpact
+	}
+	// the impact 1 - A / (mid·(B + C·x)) is at most n/d while B + C·x <= A·d / (mid·(d - n))
+	n, d := priceImpact.Numerator, priceImpact.Denominator
+	if d.Sign() < 0 {
+		n, d = new(big.Int).Neg(n), new(big.Int).Neg(d)
+	}
+	rest := new(big.Int).Sub(d, n)
+	numerator := new(big.Int).Mul(c.A, c.midPrice.Denominator)
+	numerator.Mul(numerator, d)
+	denominator := new(big.Int).Mul(c.midPrice.Numerator, rest)
+	numerator.Sub(numerator, new(big.Int).Mul(c.B, denominator))
+	if numerator.Sign() < 0 {
+		return nil, ErrUnreachablePrice
+	}
+	return numerator.Quo(numerator, denominator.Mul(denominator, c.C)), nil
+}
@@ -0,0 +1,159 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func curveRoute(t *testing.T, reserves ...[2]int64) *entities.Route {
+	tokens := make([]*core.Token, len(reserves)+1)
+	for i := range tokens {
+		tokens[i] = core.NewToken(1, common.BigToAddress(big.NewInt(int64(i+1))), 18, "t", "t")
+	}
+	pairs := make([]*entities.Pair, len(reserves))
+	for i, r := range reserves {
+		pair, err := entities.NewPair(core.FromRawAmount(tokens[i], big.NewInt(r[0])), core.FromRawAmount(tokens[i+1], big.NewInt(r[1])), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs[i] = pair
+	}
+	route, err := entities.NewRoute(pairs, tokens[0], tokens[len(tokens)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return route
+}
+
+func TestCurve(t *testing.T) {
+	routes := []*entities.Route{
+		curveRoute(t, [2]int64{1e6, 2e6}),
+		curveRoute(t, [2]int64{1e6, 2e6}, [2]int64{3e6, 1e6}),
+		curveRoute(t, [2]int64{12345, 67890}, [2]int64{5e9, 7e8}, [2]int64{1e6, 1e7}),
+	}
+	amounts := []int64{1, 3, 17, 1000, 54321, 999999}
+	for i, route := range routes {
+		curve, err := route.Curve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, amount := range amounts {
+			trade, err := entities.NewTrade(route, core.FromRawAmount(route.Input, big.NewInt(amount)), entities.ExactInput)
+			if err != nil {
+				continue
+			}
+			output, err := curve.GetAmountOut(big.NewInt(amount))
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := new(big.Int).Sub(output, trade.OutputAmount().Quotient())
+			if diff.Sign() < 0 || diff.Cmp(curve.OutputRounding()) > 0 || (i == 0 && diff.Sign() != 0) {
+				t.Errorf("route %d amount %d: expect[%s within %s], but got[%s]", i, amount, trade.OutputAmount().Quotient(), curve.OutputRounding(), output)
+			}
+
+			trade, err = entities.NewTrade(route, core.FromRawAmount(route.Output, big.NewInt(amount)), entities.ExactOutput)
+			if err != nil {
+				continue
+			}
+			input, err := curve.GetAmountIn(big.NewInt(amount))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rounding, err := curve.InputRounding(big.NewInt(amount))
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff = new(big.Int).Sub(trade.InputAmount().Quotient(), input)
+			if diff.Sign() < 0 || diff.Cmp(rounding) > 0 || (i == 0 && diff.Sign() != 0) {
+				t.Errorf("route %d amount %d: expect[%s within %s], but got[%s]", i, amount, trade.InputAmount().Quotient(), rounding, input)
+			}
+		}
+	}
+
+	// the output approaches A/C but never reaches it
+	curve, _ := routes[1].Curve()
+	most := new(big.Int).Quo(curve.A, curve.C)
+	if _, err := curve.GetAmountIn(most.Add(most, big.NewInt(1))); !errors.Is(err, entities.ErrInsufficientReserves) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrInsufficientReserves, err)
+	}
+}
+
+func TestCurvePrices(t *testing.T) {
+	route := curveRoute(t, [2]int64{1e6, 2e6}, [2]int64{3e6, 1e6})
+	curve, err := route.Curve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the marginal price at zero size is the mid price net of the fees
+	midPrice, _ := route.MidPrice()
+	fees := core.NewFraction(big.NewInt(997*997), big.NewInt(1000*1000))
+	if !curve.MarginalPrice(big.NewInt(0)).EqualTo(midPrice.Fraction.Multiply(fees)) {
+		t.Errorf("expect[%s], but got[%s]", midPrice.ToSignificant(6), curve.MarginalPrice(big.NewInt(0)).ToSignificant(6))
+	}
+
+	// half the price at zero size
+	target := core.NewPrice(route.Input, route.Output, new(big.Int).Mul(big.NewInt(2), curve.B), curve.A)
+	amount, err := curve.AmountInForPrice(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := new(big.Int).Add(amount, big.NewInt(1))
+	if curve.MarginalPrice(amount).LessThan(target.Fraction) || !curve.MarginalPrice(next).LessThan(target.Fraction) {
+		t.Errorf("expect[largest amount at %s], but got[%s]", target.ToSignificant(6), amount)
+	}
+	if _, err := curve.AmountInForPrice(midPrice); !errors.Is(err, entities.ErrUnreachablePrice) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnreachablePrice, err)
+	}
+
+	impact := core.NewPercent(big.NewInt(5), big.NewInt(100))
+	amount, err = curve.AmountInForPriceImpact(impact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.NewTrade(route, core.FromRawAmount(route.Input, amount), entities.ExactInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.PriceImpact.ToFixed(2) != "5.00" {
+		t.Errorf("expect[5.00], but got[%s]", trade.PriceImpact.ToFixed(2))
+	}
+	if _, err := curve.AmountInForPriceImpact(core.NewPercent(big.NewInt(1), big.NewInt(1000))); !errors.Is(err, entities.ErrUnreachablePrice) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnreachablePrice, err)
+	}
+	if _, err := curve.AmountInForPriceImpact(core.NewPercent(big.NewInt(1), big.NewInt(1))); !errors.Is(err, entities.ErrInvalidPriceImpact) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrInvalidPriceImpact, err)
+	}
+}
+
+func TestCurveCopy(t *testing.T) {
+	route := curveRoute(t, [2]int64{1e6, 2e6})
+	curve, err := route.Curve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve.A.SetInt64(1)
+	curve.C.SetInt64(1)
+	if entities.B997.Int64() != 997 {
+		t.Errorf("expect[997], but got[%s]", entities.B997)
+	}
+
+	cached, err := route.Curve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.A.Int64() != 997*2e6 || cached.C.Int64() != 997 {
+		t.Errorf("expect[%d and 997], but got[%s and %s]", int64(997*2e6), cached.A, cached.C)
+	}
+	fresh, err := curveRoute(t, [2]int64{1e6, 2e6}).Curve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.C.Int64() != 997 {
+		t.Errorf("expect[997], but got[%s]", fresh.C)
+	}
+}
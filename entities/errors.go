@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PairError is an error of an operation on a pair. It wraps a sentinel such as ErrDiffToken or
+// ErrInsufficientReserves, so it matches it with errors.Is, and tells which pair, token and amount were involved.
+type PairError struct {
+	Err      error
+	Pair     common.Address
+	Reserve0 *core.CurrencyAmount // Reserves of the pair at the time of the operation
+	Reserve1 *core.CurrencyAmount
+	Token    *core.Token          // The token the operation was given, if any
+	Amount   *core.CurrencyAmount // The amount the operation was given, if any
+}
+
+func (e *PairError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: pair %s (%s %s, %s %s)", e.Err, e.Pair.Hex(),
+		e.Reserve0.Quotient(), e.Reserve0.Currency.Symbol(), e.Reserve1.Quotient(), e.Reserve1.Currency.Symbol())
+	if e.Token != nil {
+		fmt.Fprintf(&b, ", token %s %s", e.Token.Symbol(), e.Token.Address.Hex())
+	}
+	if e.Amount != nil {
+		fmt.Fprintf(&b, ", amount %s %s", e.Amount.Quotient(), e.Amount.Currency.Symbol())
+	}
+	return b.String()
+}
+
+func (e *PairError) Unwrap() error {
+	return e.Err
+}
+
+// pairError returns the error of the pair for the token and amount, either may be nil
+func (p *Pair) pairError(err error, token *core.Token, amount *core.CurrencyAmount) *PairError {
+	if token == nil && amount != nil {
+		token = WrappedOf(amount.Currency)
+	}
+	return &PairError{
+		Err:      err,
+		Pair:     p.Address,
+		Reserve0: p.Reserve0(),
+		Reserve1: p.Reserve1(),
+		Token:    token,
+		Amount:   amount,
+	}
+}
+
+// CurrencyError reports a currency other than the expected one. It wraps ErrInvalidCurrency.
+type CurrencyError struct {
+	Expected core.Currency
+	Actual   core.Currency
+}
+
+func (e *CurrencyError) Error() string {
+	return fmt.Sprintf("%s: expected %s, got %s", ErrInvalidCurrency, currencyName(e.Expected), currencyName(e.Actual))
+}
+
+func (e *CurrencyError) Unwrap() error {
+	return ErrInvalidCurrency
+}
+
+func currencyName(currency core.Currency) string {
+	if currency.IsNative() {
+		return currency.Symbol()
+	}
+	return fmt.Sprintf("%s %s", currency.Symbol(), currency.Wrapped().Address.Hex())
+}
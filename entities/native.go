@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrUnknownNative = errors.New("no native currency registered for chain")
+
+// NativeCurrency is the native currency of a chain, e.g. MATIC, wrapped by a token of its own rather than
+// core.WETH9. Like core.Ether, it only equals a NativeCurrency of the same chain.
+type NativeCurrency struct {
+	chainID  uint
+	decimals uint
+	symbol   string
+	name     string
+	wrapped  *core.Token
+}
+
+// NewNativeCurrency creates the native currency of the chain of the wrapped token
+func NewNativeCurrency(wrapped *core.Token, decimals uint, symbol, name string) *NativeCurrency {
+	return &NativeCurrency{
+		chainID:  wrapped.ChainId(),
+		decimals: decimals,
+		symbol:   symbol,
+		name:     name,
+		wrapped:  wrapped,
+	}
+}
+
+func (n *NativeCurrency) IsNative() bool       { return true }
+func (n *NativeCurrency) IsToken() bool        { return false }
+func (n *NativeCurrency) ChainId() uint        { return n.chainID }
+func (n *NativeCurrency) Decimals() uint       { return n.decimals }
+func (n *NativeCurrency) Symbol() string       { return n.symbol }
+func (n *NativeCurrency) Name() string         { return n.name }
+func (n *NativeCurrency) Wrapped() *core.Token { return n.wrapped }
+
+func (n *NativeCurrency) Equal(other core.Currency) bool {
+	v, ok := other.(*NativeCurrency)
+	return ok && v.chainID == n.chainID
+}
+
+func nativeOf(chainID uint, wrapped, symbol, name, wrappedSymbol, wrappedName string) *NativeCurrency {
+	return NewNativeCurrency(core.NewToken(chainID, common.HexToAddress(wrapped), 18, wrappedSymbol, wrappedName), 18, symbol, name)
+}
+
+// NativeCurrencies by chain ID. Register the native currency of other chains before use, it is not safe for
+// concurrent use with lookups.
+var NativeCurrencies = map[uint]*NativeCurrency{
+	1:     NewNativeCurrency(core.WETH9[1], 18, "ETH", "Ether"),
+	10:    nativeOf(10, "0x4200000000000000000000000000000000000006", "ETH", "Ether", "WETH", "Wrapped Ether"),
+	56:    nativeOf(56, "0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c", "BNB", "BNB", "WBNB", "Wrapped BNB"),
+	100:   nativeOf(100, "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d", "xDAI", "xDAI", "WXDAI", "Wrapped XDAI"),
+	137:   nativeOf(137, "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270", "MATIC", "Matic", "WMATIC", "Wrapped Matic"),
+	250:   nativeOf(250, "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83", "FTM", "Fantom", "WFTM", "Wrapped Fantom"),
+	8453:  nativeOf(8453, "0x4200000000000000000000000000000000000006", "ETH", "Ether", "WETH", "Wrapped Ether"),
+	42161: nativeOf(42161, "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1", "ETH", "Ether", "WETH", "Wrapped Ether"),
+	43114: nativeOf(43114, "0xB31f66AA3C1e785363F0875A1B74E27b85FD66c7", "AVAX", "Avalanche", "WAVAX", "Wrapped AVAX"),
+}
+
+// NativeOnChain returns the registered native currency of the chain
+func NativeOnChain(chainID uint) (*NativeCurrency, error) {
+	if native, ok := NativeCurrencies[chainID]; ok {
+		return native, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnknownNative, chainID)
+}
+
+// WrappedOf returns the token the currency trades as in pairs: the wrapped token of a NativeCurrency, the registered
+// wrapped token of another native currency, e.g. core.Ether, falling back to Currency.Wrapped, i.e. core.WETH9.
+// Use it rather than Currency.Wrapped, which is nil for core.Ether on chains without core.WETH9.
+func WrappedOf(currency core.Currency) *core.Token {
+	if native, ok := currency.(*NativeCurrency); ok {
+		return native.wrapped
+	}
+	if currency.IsNative() {
+		if native, ok := NativeCurrencies[currency.ChainId()]; ok {
+			return native.wrapped
+		}
+	}
+	return currency.Wrapped()
+}
+
+// ResolveWrapped returns the token of WrappedOf, or ErrUnknownNative if the currency is a native currency of a chain
+// without a registered wrapped token nor core.WETH9
+func ResolveWrapped(currency core.Currency) (*core.Token, error) {
+	if wrapped := WrappedOf(currency); wrapped != nil {
+		return wrapped, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnknownNative, currency.ChainId())
+}
+
+// WrappedAmount returns the amount in the token the currency trades as in pairs, as CurrencyAmount.Wrapped does
+// with the token of WrappedOf
+func WrappedAmount(amount *core.CurrencyAmount) *core.CurrencyAmount {
+	if amount.Currency.IsToken() {
+		return amount
+	}
+	return core.FromFractionalAmount(WrappedOf(amount.Currency), amount.Numerator, amount.Denominator)
+}
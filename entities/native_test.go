@@ -0,0 +1,119 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func TestNativeCurrency(t *testing.T) {
+	matic, err := entities.NativeOnChain(137)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matic.Symbol() != "MATIC" || matic.Wrapped().Symbol() != "WMATIC" || !matic.IsNative() || matic.IsToken() {
+		t.Errorf("expect[MATIC wrapped as WMATIC], but got[%s wrapped as %s]", matic.Symbol(), matic.Wrapped().Symbol())
+	}
+	if _, err := entities.NativeOnChain(999999); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if !entities.IsWrap(matic, matic.Wrapped()) || entities.IsWrap(matic, matic) {
+		t.Errorf("expect[MATIC and WMATIC to wrap]")
+	}
+
+	// core.Ether of a chain without core.WETH9 resolves through the registry
+	if wrapped := entities.WrappedOf(core.EtherOnChain(137)); wrapped != matic.Wrapped() {
+		t.Errorf("expect[%s], but got[%v]", matic.Wrapped().Address.Hex(), wrapped)
+	}
+	if wrapped := entities.WrappedOf(core.EtherOnChain(1)); wrapped != core.WETH9[1] {
+		t.Errorf("expect[%s], but got[%v]", core.WETH9[1].Address.Hex(), wrapped)
+	}
+
+	usdc := core.NewToken(137, common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"), 6, "USDC", "USD Coin")
+	pair, err := entities.NewPair(core.FromRawAmount(matic.Wrapped(), big.NewInt(1000)), core.FromRawAmount(usdc, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, err := entities.NewRoute([]*entities.Pair{pair}, matic, usdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Path[0] != matic.Wrapped() {
+		t.Errorf("expect[%s], but got[%s]", matic.Wrapped().Symbol(), route.Path[0].Symbol())
+	}
+	trades, err := entities.BestTradeExactIn([]*entities.Pair{pair}, core.FromRawAmount(matic, big.NewInt(100)), usdc, nil, nil, nil, nil)
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("expect[1 trade], but got[%d, %v]", len(trades), err)
+	}
+	if !trades[0].InputAmount().Currency.Equal(matic) || trades[0].OutputAmount().Quotient().Int64() != 90 {
+		t.Errorf("expect[100 MATIC for 90 USDC], but got[%s for %s]", trades[0].InputAmount().Quotient(), trades[0].OutputAmount().Quotient())
+	}
+
+	// core.Ether of the chain trades through the registered wrapped token too
+	ether := core.EtherOnChain(137)
+	route, err = entities.NewRoute([]*entities.Pair{pair}, ether, usdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.NewTrade(route, core.FromRawAmount(ether, big.NewInt(100)), entities.ExactInput)
+	if err != nil || trade.OutputAmount().Quotient().Int64() != 90 {
+		t.Fatalf("expect[90], but got[%v]", err)
+	}
+	trades, err = entities.BestTradeExactOut([]*entities.Pair{pair}, usdc, core.FromRawAmount(ether, big.NewInt(90)), nil, nil, nil, nil)
+	if err != nil || len(trades) != 1 || trades[0].InputAmount().Quotient().Int64() != 100 {
+		t.Fatalf("expect[1 trade of 100 USDC], but got[%d, %v]", len(trades), err)
+	}
+}
+
+func TestNativeCurrencyEqual(t *testing.T) {
+	native := entities.NativeCurrencies[1]
+	ether := core.EtherOnChain(1)
+	if native.Equal(ether) || ether.Equal(native) {
+		t.Errorf("expect[the registry native and core.Ether to differ both ways]")
+	}
+	if !native.Equal(entities.NativeCurrencies[1]) || native.Equal(entities.NativeCurrencies[10]) {
+		t.Errorf("expect[native currencies to equal by chain]")
+	}
+	if entities.IsWrap(ether, native) || entities.IsWrap(native, ether) {
+		t.Errorf("expect[no wrap between two native currencies]")
+	}
+	if !entities.IsWrap(ether, core.WETH9[1]) || !entities.IsWrap(core.WETH9[1], native) {
+		t.Errorf("expect[native currencies to wrap into WETH9]")
+	}
+}
+
+func TestUnknownNative(t *testing.T) {
+	ether := core.EtherOnChain(999)
+	token := core.NewToken(999, common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"), 18, "T", "Token")
+	weth := core.NewToken(999, common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"), 18, "W", "Wrapped")
+	pair, err := entities.NewPair(core.FromRawAmount(weth, big.NewInt(1000)), core.FromRawAmount(token, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := entities.ResolveWrapped(ether); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if _, err := entities.NewRoute([]*entities.Pair{pair}, ether, token); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if _, err := entities.NewRoute([]*entities.Pair{pair}, token, ether); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if _, _, err := pair.GetOutputAmount(core.FromRawAmount(ether, big.NewInt(100))); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if _, _, err := pair.GetInputAmount(core.FromRawAmount(ether, big.NewInt(100))); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if _, err := entities.BestTradeExactIn([]*entities.Pair{pair}, core.FromRawAmount(ether, big.NewInt(100)), token, nil, nil, nil, nil); !errors.Is(err, entities.ErrUnknownNative) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrUnknownNative, err)
+	}
+	if entities.IsWrap(ether, weth) {
+		t.Errorf("expect[no wrap for an unknown native currency]")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/daoleno/uniswap-sdk-core/entities"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
 	"math/big"
 )
 
@@ -38,7 +39,7 @@ func GetAddress(tokenA, tokenB *entities.Token, factory common.Address, initHash
 
 // NewCurrencyAmounts creates a CurrencyAmounts
 func NewCurrencyAmounts(amountA, amountB *entities.CurrencyAmount) (CurrencyAmounts, error) {
-	ok, err := amountA.Currency.Wrapped().SortsBefore(amountB.Currency.Wrapped())
+	ok, err := WrappedOf(amountA.Currency).SortsBefore(WrappedOf(amountB.Currency))
 	if err != nil {
 		return CurrencyAmounts{}, err
 	}
@@ -54,6 +55,9 @@ type Pair struct {
 	TokenAmounts   CurrencyAmounts // sorted tokens
 	Address        common.Address  // Pair address
 	Options        *PairOptions
+
+	quoteAmounts  CurrencyAmounts // TokenAmounts when quoteReserves was set
+	quoteReserves [2]uint256.Int
 }
 
 // PairOptions for generating pair address
@@ -80,19 +84,21 @@ func NewPair(amountA, amountB *entities.CurrencyAmount, options *PairOptions) (*
 	if opts.Address != nil {
 		pairAddress = *opts.Address
 	} else {
-		pairAddress, err = GetAddress(amountA.Currency.Wrapped(), amountB.Currency.Wrapped(), opts.Factory, opts.InitCodeHash)
+		pairAddress, err = GetAddress(WrappedOf(amountA.Currency), WrappedOf(amountB.Currency), opts.Factory, opts.InitCodeHash)
 		if err != nil {
 			return nil, err
 		}
 	}
-	liquidityToken := entities.NewToken(amounts[0].Currency.Wrapped().ChainId(), pairAddress,
+	liquidityToken := entities.NewToken(WrappedOf(amounts[0].Currency).ChainId(), pairAddress,
 		18, "UNI-V2", "Uniswap V2")
-	return &Pair{
+	pair := &Pair{
 		TokenAmounts:   amounts,
 		LiquidityToken: liquidityToken,
 		Address:        pairAddress,
 		Options:        opts,
-	}, nil
+	}
+	pair.setQuoteReserves()
+	return pair, nil
 }
 
 // GetAddress returns a contract's address for a pair
@@ -103,7 +109,7 @@ func (p *Pair) GetAddress() common.Address {
 // InvolvesToken Returns true if the token is either token0 or token1
 // @param token to check
 func (p *Pair) InvolvesToken(token *entities.Token) bool {
-	return token.Equal(p.TokenAmounts[0].Currency.Wrapped()) || token.Equal(p.TokenAmounts[1].Currency.Wrapped())
+	return token.Equal(WrappedOf(p.TokenAmounts[0].Currency)) || token.Equal(WrappedOf(p.TokenAmounts[1].Currency))
 }
 
 // Token0Price Returns the current mid price of the pair in terms of token0, i.e. the ratio of reserve1 to reserve0
@@ -120,7 +126,7 @@ func (p *Pair) Token1Price() *entities.Price {
 // @param token token to return price of
 func (p *Pair) PriceOf(token *entities.Token) (*entities.Price, error) {
 	if !p.InvolvesToken(token) {
-		return nil, ErrDiffToken
+		return nil, p.pairError(ErrDiffToken, token, nil)
 	}
 
 	if token.Equal(p.Token0()) {
@@ -136,12 +142,12 @@ func (p *Pair) ChainID() uint {
 
 // Token0 returns the first token in the pair
 func (p *Pair) Token0() *entities.Token {
-	return p.TokenAmounts[0].Currency.Wrapped()
+	return WrappedOf(p.TokenAmounts[0].Currency)
 }
 
 // Token1 returns the last token in the pair
 func (p *Pair) Token1() *entities.Token {
-	return p.TokenAmounts[1].Currency.Wrapped()
+	return WrappedOf(p.TokenAmounts[1].Currency)
 }
 
 // Reserve0 returns the first CurrencyAmount in the pair
@@ -157,7 +163,7 @@ func (p *Pair) Reserve1() *entities.CurrencyAmount {
 // ReserveOf returns the CurrencyAmount that equals to the token
 func (p *Pair) ReserveOf(token *entities.Token) (*entities.CurrencyAmount, error) {
 	if !p.InvolvesToken(token) {
-		return nil, ErrDiffToken
+		return nil, p.pairError(ErrDiffToken, token, nil)
 	}
 
 	if token.Equal(p.Token0()) {
@@ -168,21 +174,25 @@ func (p *Pair) ReserveOf(token *entities.Token) (*entities.CurrencyAmount, error
 
 // GetOutputAmount returns OutputAmount and a Pair for the InputAmout
 func (p *Pair) GetOutputAmount(inputAmount *entities.CurrencyAmount) (*entities.CurrencyAmount, *Pair, error) {
-	if !p.InvolvesToken(inputAmount.Currency.Wrapped()) {
-		return nil, nil, ErrDiffToken
+	tokenIn, err := ResolveWrapped(inputAmount.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !p.InvolvesToken(tokenIn) {
+		return nil, nil, p.pairError(ErrDiffToken, nil, inputAmount)
 	}
 
 	if p.Reserve0().Quotient().Cmp(Zero) == 0 ||
 		p.Reserve1().Quotient().Cmp(Zero) == 0 {
-		return nil, nil, ErrInsufficientReserves
+		return nil, nil, p.pairError(ErrInsufficientReserves, nil, inputAmount)
 	}
 
-	inputReserve, err := p.ReserveOf(inputAmount.Currency.Wrapped())
+	inputReserve, err := p.ReserveOf(tokenIn)
 	if err != nil {
 		return nil, nil, err
 	}
 	token := p.Token0()
-	if inputAmount.Currency.Wrapped().Equal(p.Token0()) {
+	if tokenIn.Equal(p.Token0()) {
 		token = p.Token1()
 	}
 	outputReserve, err := p.ReserveOf(token)
@@ -195,10 +205,10 @@ func (p *Pair) GetOutputAmount(inputAmount *entities.CurrencyAmount) (*entities.
 	denominator := big.NewInt(0).Add(big.NewInt(0).Mul(inputReserve.Quotient(), B1000), inputAmountWithFee)
 	outputAmount := entities.FromRawAmount(token, big.NewInt(0).Div(numerator, denominator))
 	if outputAmount.Quotient().Cmp(Zero) == 0 {
-		return nil, nil, ErrInsufficientInputAmount
+		return nil, nil, p.pairError(ErrInsufficientInputAmount, nil, inputAmount)
 	}
 
-	tokenAmountA := inputAmount.Add(inputReserve)
+	tokenAmountA := inputReserve.Add(inputAmount)
 	tokenAmountB := outputReserve.Subtract(outputAmount)
 	pair, err := NewPair(tokenAmountA, tokenAmountB, p.Options)
 	if err != nil {
@@ -210,22 +220,26 @@ func (p *Pair) GetOutputAmount(inputAmount *entities.CurrencyAmount) (*entities.
 
 // GetInputAmount returns InputAmout and a Pair for the OutputAmount
 func (p *Pair) GetInputAmount(outputAmount *entities.CurrencyAmount) (*entities.CurrencyAmount, *Pair, error) {
-	if !p.InvolvesToken(outputAmount.Currency.Wrapped()) {
-		return nil, nil, ErrDiffToken
+	tokenOut, err := ResolveWrapped(outputAmount.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !p.InvolvesToken(tokenOut) {
+		return nil, nil, p.pairError(ErrDiffToken, nil, outputAmount)
 	}
 
-	outputReserve, err := p.ReserveOf(outputAmount.Currency.Wrapped())
+	outputReserve, err := p.ReserveOf(tokenOut)
 	if err != nil {
 		return nil, nil, err
 	}
 	if p.Reserve0().Quotient().Cmp(Zero) == 0 ||
 		p.Reserve1().Quotient().Cmp(Zero) == 0 ||
 		outputAmount.Quotient().Cmp(outputReserve.Quotient()) >= 0 {
-		return nil, nil, ErrInsufficientReserves
+		return nil, nil, p.pairError(ErrInsufficientReserves, nil, outputAmount)
 	}
 
 	token := p.Token0()
-	if outputAmount.Currency.Wrapped().Equal(p.Token0()) {
+	if tokenOut.Equal(p.Token0()) {
 		token = p.Token1()
 	}
 	inputReserve, err := p.ReserveOf(token)
@@ -244,7 +258,7 @@ func (p *Pair) GetInputAmount(outputAmount *entities.CurrencyAmount) (*entities.
 		return nil, nil, err
 	}
 
-	tokenAmountA := inputAmount.Add(inputReserve)
+	tokenAmountA := inputReserve.Add(inputAmount)
 	tokenAmountB := outputReserve.Subtract(outputAmount)
 	pair, err := NewPair(tokenAmountA, tokenAmountB, p.Options)
 	if err != nil {
@@ -254,29 +268,38 @@ func (p *Pair) GetInputAmount(outputAmount *entities.CurrencyAmount) (*entities.
 	return inputAmount, pair, nil
 }
 
-// GetLiquidityMinted returns liquidity minted CurrencyAmount
-func (p *Pair) GetLiquidityMinted(totalSupply, tokenAmountA, tokenAmountB *entities.CurrencyAmount) (*entities.CurrencyAmount, error) {
-	if !p.LiquidityToken.Equal(totalSupply.Currency.Wrapped()) {
-		return nil, ErrDiffToken
+// GetLiquidityMinted returns liquidity minted CurrencyAmount.
+// If feeOn is true, the protocol fee minted to feeTo before the deposit is added to the total supply first.
+func (p *Pair) GetLiquidityMinted(totalSupply, tokenAmountA, tokenAmountB *entities.CurrencyAmount, feeOn bool, kLast *big.Int) (*entities.CurrencyAmount, error) {
+	if !p.LiquidityToken.Equal(WrappedOf(totalSupply.Currency)) {
+		return nil, p.pairError(ErrDiffToken, nil, totalSupply)
 	}
 
 	tokenAmounts, err := NewCurrencyAmounts(tokenAmountA, tokenAmountB)
 	if err != nil {
 		return nil, err
 	}
-	if !(tokenAmounts[0].Currency.Wrapped().Equal(p.Token0()) && tokenAmounts[1].Currency.Wrapped().Equal(p.Token1())) {
-		return nil, ErrDiffToken
+	if !WrappedOf(tokenAmounts[0].Currency).Equal(p.Token0()) {
+		return nil, p.pairError(ErrDiffToken, nil, tokenAmounts[0])
+	}
+	if !WrappedOf(tokenAmounts[1].Currency).Equal(p.Token1()) {
+		return nil, p.pairError(ErrDiffToken, nil, tokenAmounts[1])
+	}
+
+	totalSupplyAdjusted, err := p.adjustTotalSupply(totalSupply, feeOn, kLast)
+	if err != nil {
+		return nil, err
 	}
 
 	var liquidity *big.Int
-	if totalSupply.Quotient().Cmp(Zero) == 0 {
+	if totalSupplyAdjusted.Quotient().Cmp(Zero) == 0 {
 		liquidity = big.NewInt(0).Mul(tokenAmounts[0].Quotient(), tokenAmounts[1].Quotient())
 		liquidity.Sqrt(liquidity)
 		liquidity.Sub(liquidity, MinimumLiquidity)
 	} else {
-		amount0 := big.NewInt(0).Mul(tokenAmounts[0].Quotient(), totalSupply.Quotient())
+		amount0 := big.NewInt(0).Mul(tokenAmounts[0].Quotient(), totalSupplyAdjusted.Quotient())
 		amount0.Div(amount0, p.Reserve0().Quotient())
-		amount1 := big.NewInt(0).Mul(tokenAmounts[1].Quotient(), totalSupply.Quotient())
+		amount1 := big.NewInt(0).Mul(tokenAmounts[1].Quotient(), totalSupplyAdjusted.Quotient())
 		amount1.Div(amount1, p.Reserve1().Quotient())
 		liquidity = amount0
 		if liquidity.Cmp(amount1) > 0 {
@@ -285,7 +308,7 @@ func (p *Pair) GetLiquidityMinted(totalSupply, tokenAmountA, tokenAmountB *entit
 	}
 
 	if liquidity.Cmp(Zero) <= 0 {
-		return nil, ErrInsufficientInputAmount
+		return nil, p.pairError(ErrInsufficientInputAmount, nil, tokenAmountA)
 	}
 
 	return entities.FromRawAmount(p.LiquidityToken, liquidity), nil
@@ -293,8 +316,14 @@ func (p *Pair) GetLiquidityMinted(totalSupply, tokenAmountA, tokenAmountB *entit
 
 // GetLiquidityValue returns liquidity value CurrencyAmount
 func (p *Pair) GetLiquidityValue(token *entities.Token, totalSupply, liquidity *entities.CurrencyAmount, feeOn bool, kLast *big.Int) (*entities.CurrencyAmount, error) {
-	if !p.InvolvesToken(token) || !p.LiquidityToken.Equal(totalSupply.Currency.Wrapped()) || !p.LiquidityToken.Equal(liquidity.Currency.Wrapped()) {
-		return nil, ErrDiffToken
+	if !p.InvolvesToken(token) {
+		return nil, p.pairError(ErrDiffToken, token, nil)
+	}
+	if !p.LiquidityToken.Equal(WrappedOf(totalSupply.Currency)) {
+		return nil, p.pairError(ErrDiffToken, nil, totalSupply)
+	}
+	if !p.LiquidityToken.Equal(WrappedOf(liquidity.Currency)) {
+		return nil, p.pairError(ErrDiffToken, nil, liquidity)
 	}
 	if liquidity.Quotient().Cmp(totalSupply.Quotient()) > 0 {
 		return nil, ErrInvalidLiquidity
@@ -315,29 +344,43 @@ func (p *Pair) GetLiquidityValue(token *entities.Token, totalSupply, liquidity *
 	return entities.FromRawAmount(token, amount), nil
 }
 
-func (p *Pair) adjustTotalSupply(totalSupply *entities.CurrencyAmount, feeOn bool, kLast *big.Int) (*entities.CurrencyAmount, error) {
-	if !feeOn {
-		return totalSupply, nil
+// GetProtocolFeeLiquidity returns the liquidity the pair mints to feeTo for the protocol fee before the next
+// mint or burn, i.e. 1/6th of the growth in sqrt(k) since kLast, as _mintFee does.
+// @param totalSupply total supply of the liquidity token
+// @param kLast the value of the kLast of the pair
+func (p *Pair) GetProtocolFeeLiquidity(totalSupply *entities.CurrencyAmount, kLast *big.Int) (*entities.CurrencyAmount, error) {
+	if !p.LiquidityToken.Equal(WrappedOf(totalSupply.Currency)) {
+		return nil, p.pairError(ErrDiffToken, nil, totalSupply)
 	}
-
 	if kLast == nil {
 		return nil, ErrInvalidKLast
 	}
 	if kLast.Cmp(Zero) == 0 {
-		return totalSupply, nil
+		return entities.FromRawAmount(p.LiquidityToken, big.NewInt(0)), nil
 	}
 
 	rootK := big.NewInt(0).Mul(p.Reserve0().Quotient(), p.Reserve1().Quotient())
 	rootK.Sqrt(rootK)
 	rootKLast := big.NewInt(0).Sqrt(kLast)
 	if rootK.Cmp(rootKLast) <= 0 {
-		return totalSupply, nil
+		return entities.FromRawAmount(p.LiquidityToken, big.NewInt(0)), nil
 	}
 
 	numerator := big.NewInt(0).Sub(rootK, rootKLast)
 	numerator.Mul(numerator, totalSupply.Quotient())
 	denominator := big.NewInt(0).Mul(rootK, Five)
 	denominator.Add(denominator, rootKLast)
-	tokenAmount := entities.FromFractionalAmount(p.LiquidityToken, numerator, denominator)
-	return totalSupply.Add(tokenAmount), nil
+	return entities.FromRawAmount(p.LiquidityToken, numerator.Div(numerator, denominator)), nil
+}
+
+func (p *Pair) adjustTotalSupply(totalSupply *entities.CurrencyAmount, feeOn bool, kLast *big.Int) (*entities.CurrencyAmount, error) {
+	if !feeOn {
+		return totalSupply, nil
+	}
+
+	feeLiquidity, err := p.GetProtocolFeeLiquidity(totalSupply, kLast)
+	if err != nil {
+		return nil, err
+	}
+	return totalSupply.Add(feeLiquidity), nil
 }
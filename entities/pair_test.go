@@ -1,6 +1,7 @@
 package entities_test
 
 import (
+	"errors"
 	core "github.com/daoleno/uniswap-sdk-core/entities"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/vaulverin/uniswapv2-sdk/entities"
@@ -168,9 +169,13 @@ func TestPair(t *testing.T) {
 			// throws if invalid token
 			expect := entities.ErrDiffToken
 			_, output := p.PriceOf(core.WETH9[1])
-			if expect != output {
+			if !errors.Is(output, expect) {
 				t.Errorf("expect[%+v], but got[%+v]", expect, output)
 			}
+			var pairErr *entities.PairError
+			if !errors.As(output, &pairErr) || pairErr.Pair != p.Address || !pairErr.Token.Equal(core.WETH9[1]) {
+				t.Errorf("expect[%+v], but got[%+v]", p.Address, output)
+			}
 		}
 	}
 
@@ -203,7 +208,7 @@ func TestPair(t *testing.T) {
 			// throws if not in the pair
 			expect := entities.ErrDiffToken
 			_, output := pairB.ReserveOf(core.WETH9[1])
-			if expect != output {
+			if !errors.Is(output, expect) {
 				t.Errorf("expect[%+v], but got[%+v]", expect, output)
 			}
 		}
@@ -253,15 +258,15 @@ func TestPair(t *testing.T) {
 				tokenAmountB := core.FromRawAmount(tokenB, big.NewInt(1000))
 				// getLiquidityMinted:0
 				expect := entities.ErrInsufficientInputAmount
-				_, output := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB)
-				if expect != output {
+				_, output := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB, false, nil)
+				if !errors.Is(output, expect) {
 					t.Errorf("expect[%+v], but got[%+v]", expect, output)
 				}
 
 				tokenAmountA = core.FromRawAmount(tokenA, big.NewInt(1000000))
 				tokenAmountB = core.FromRawAmount(tokenB, big.NewInt(1))
-				_, output = p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB)
-				if expect != output {
+				_, output = p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB, false, nil)
+				if !errors.Is(output, expect) {
 					t.Errorf("expect[%+v], but got[%+v]", expect, output)
 				}
 
@@ -269,7 +274,7 @@ func TestPair(t *testing.T) {
 				tokenAmountB = core.FromRawAmount(tokenB, big.NewInt(1001))
 				{
 					expect := "1"
-					liquidity, _ := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB)
+					liquidity, _ := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB, false, nil)
 					output := liquidity.Quotient().String()
 					if expect != output {
 						t.Errorf("expect[%+v], but got[%+v]", expect, output)
@@ -286,7 +291,7 @@ func TestPair(t *testing.T) {
 				tokenAmountA = core.FromRawAmount(tokenA, big.NewInt(2000))
 				tokenAmountB = core.FromRawAmount(tokenB, big.NewInt(2000))
 				expect := "2000"
-				liquidity, _ := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB)
+				liquidity, _ := p.GetLiquidityMinted(tokenAmount, tokenAmountA, tokenAmountB, false, nil)
 				output := liquidity.Quotient().String()
 				if expect != output {
 					t.Errorf("expect[%+v], but got[%+v]", expect, output)
@@ -369,6 +374,53 @@ func TestPair(t *testing.T) {
 					}
 				}
 			}
+
+			// getProtocolFeeLiquidity
+			{
+				feeLiquidity, err := p.GetProtocolFeeLiquidity(tokenAmount500, big.NewInt(250000))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expect := "45" // floor(500 * (1000 - 500) / (1000 * 5 + 500))
+				output := feeLiquidity.Quotient().String()
+				if expect != output {
+					t.Errorf("expect[%+v], but got[%+v]", expect, output)
+				}
+
+				feeLiquidity, _ = p.GetProtocolFeeLiquidity(tokenAmount500, big.NewInt(1000000))
+				if feeLiquidity.Quotient().Cmp(entities.Zero) != 0 {
+					t.Errorf("expect[0], but got[%+v]", feeLiquidity.Quotient())
+				}
+
+				_, err = p.GetProtocolFeeLiquidity(tokenAmount500, nil)
+				if err != entities.ErrInvalidKLast {
+					t.Errorf("expect[%+v], but got[%+v]", entities.ErrInvalidKLast, err)
+				}
+			}
+
+			// getLiquidityMinted:feeOn
+			{
+				tokenAmountA := core.FromRawAmount(tokenA, big.NewInt(100))
+				tokenAmountB := core.FromRawAmount(tokenB, big.NewInt(100))
+				liquidity, _ := p.GetLiquidityMinted(tokenAmount500, tokenAmountA, tokenAmountB, false, nil)
+				expect := "50"
+				output := liquidity.Quotient().String()
+				if expect != output {
+					t.Errorf("expect[%+v], but got[%+v]", expect, output)
+				}
+
+				liquidity, _ = p.GetLiquidityMinted(tokenAmount500, tokenAmountA, tokenAmountB, true, big.NewInt(250000))
+				expect = "54" // floor(100 * (500 + 45) / 1000)
+				output = liquidity.Quotient().String()
+				if expect != output {
+					t.Errorf("expect[%+v], but got[%+v]", expect, output)
+				}
+
+				_, err := p.GetLiquidityMinted(tokenAmount500, tokenAmountA, tokenAmountB, true, nil)
+				if err != entities.ErrInvalidKLast {
+					t.Errorf("expect[%+v], but got[%+v]", entities.ErrInvalidKLast, err)
+				}
+			}
 		}
 	}
 }
@@ -0,0 +1,162 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+var (
+	ErrInvalidEntry = errors.New("invalid position entry")
+)
+
+// Position is an amount of liquidity tokens together with the pair state it was entered at
+type Position struct {
+	Liquidity        *core.CurrencyAmount // Liquidity tokens held
+	EntryPair        *Pair                // Pair reserves when the position was entered
+	EntryTotalSupply *core.CurrencyAmount // Total supply of the liquidity token when the position was entered
+}
+
+// PositionValue is the state of a position at the current pair state, valued in a quote token
+type PositionValue struct {
+	Amount0         *core.CurrencyAmount // Current amount of token0 the liquidity is worth
+	Amount1         *core.CurrencyAmount // Current amount of token1 the liquidity is worth
+	Value           *core.CurrencyAmount // Current value of the position in the quote token
+	HoldValue       *core.CurrencyAmount // Current value of the amounts deposited at entry, had they been held
+	FeesEarned      *core.CurrencyAmount // Part of Value earned by swap fees, from the growth of sqrt(k) per liquidity token
+	FeeGrowth       *core.Percent        // Growth of sqrt(k) per liquidity token since entry
+	ImpermanentLoss *core.Percent        // Loss of the value excluding fees relative to HoldValue, positive is a loss
+}
+
+// NewPosition creates a Position
+// @param entryPair the pair at the time the position was entered
+// @param entryTotalSupply the total supply of the liquidity token at the time the position was entered
+// @param liquidity the liquidity tokens held
+func NewPosition(entryPair *Pair, entryTotalSupply, liquidity *core.CurrencyAmount) (*Position, error) {
+	if !entryPair.LiquidityToken.Equal(WrappedOf(entryTotalSupply.Currency)) {
+		return nil, entryPair.pairError(ErrDiffToken, nil, entryTotalSupply)
+	}
+	if !entryPair.LiquidityToken.Equal(WrappedOf(liquidity.Currency)) {
+		return nil, entryPair.pairError(ErrDiffToken, nil, liquidity)
+	}
+	if entryTotalSupply.Quotient().Cmp(Zero) <= 0 {
+		return nil, ErrInvalidEntry
+	}
+	if liquidity.Quotient().Cmp(Zero) <= 0 || liquidity.Quotient().Cmp(entryTotalSupply.Quotient()) > 0 {
+		return nil, ErrInvalidLiquidity
+	}
+	return &Position{
+		Liquidity:        liquidity,
+		EntryPair:        entryPair,
+		EntryTotalSupply: entryTotalSupply,
+	}, nil
+}
+
+// EntryAmounts returns the amounts of token0 and token1 the liquidity was worth at entry
+func (p *Position) EntryAmounts() (*core.CurrencyAmount, *core.CurrencyAmount, error) {
+	amount0, err := p.EntryPair.GetLiquidityValue(p.EntryPair.Token0(), p.EntryTotalSupply, p.Liquidity, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	amount1, err := p.EntryPair.GetLiquidityValue(p.EntryPair.Token1(), p.EntryTotalSupply, p.Liquidity, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return amount0, amount1, nil
+}
+
+// Value returns the value of the position at the current pair state.
+// Amounts are valued at the current mid price of the pair.
+// Returns ErrInsufficientReserves if a current reserve is empty, and ErrInvalidEntry if the liquidity was worth
+// nothing at entry.
+// @param pair the current pair
+// @param totalSupply the current total supply of the liquidity token
+// @param feeOn whether the protocol fee is on
+// @param kLast the value of the kLast of the pair, required if feeOn is true
+// @param quote the token to value the position in, token0 or token1 of the pair
+func (p *Position) Value(pair *Pair, totalSupply *core.CurrencyAmount, feeOn bool, kLast *big.Int, quote *core.Token) (*PositionValue, error) {
+	if !pair.LiquidityToken.Equal(p.EntryPair.LiquidityToken) {
+		return nil, pair.pairError(ErrDiffToken, p.EntryPair.LiquidityToken, nil)
+	}
+	if !pair.InvolvesToken(quote) {
+		return nil, pair.pairError(ErrDiffToken, quote, nil)
+	}
+	// the amounts are valued at the mid price, and the fees from sqrt(k), of the current reserves
+	if pair.empty() {
+		return nil, pair.pairError(ErrInsufficientReserves, nil, nil)
+	}
+
+	amount0, err := pair.GetLiquidityValue(pair.Token0(), totalSupply, p.Liquidity, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+	amount1, err := pair.GetLiquidityValue(pair.Token1(), totalSupply, p.Liquidity, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+	entryAmount0, entryAmount1, err := p.EntryAmounts()
+	if err != nil {
+		return nil, err
+	}
+	value, err := valueIn(pair, quote, amount0, amount1)
+	if err != nil {
+		return nil, err
+	}
+	holdValue, err := valueIn(pair, quote, entryAmount0, entryAmount1)
+	if err != nil {
+		return nil, err
+	}
+
+	if holdValue.Quotient().Sign() <= 0 {
+		return nil, ErrInvalidEntry
+	}
+
+	totalSupplyAdjusted, err := pair.adjustTotalSupply(totalSupply, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+	if totalSupplyAdjusted.Quotient().Sign() <= 0 {
+		return nil, ErrInvalidLiquidity
+	}
+	// sqrt(k) per liquidity token now relative to entry
+	entryRootK := big.NewInt(0).Mul(p.EntryPair.Reserve0().Quotient(), p.EntryPair.Reserve1().Quotient())
+	entryRootK.Sqrt(entryRootK)
+	rootK := big.NewInt(0).Mul(pair.Reserve0().Quotient(), pair.Reserve1().Quotient())
+	rootK.Sqrt(rootK)
+	if entryRootK.Cmp(Zero) == 0 {
+		return nil, ErrInvalidEntry
+	}
+	growth := core.NewFraction(rootK, totalSupplyAdjusted.Quotient()).
+		Divide(core.NewFraction(entryRootK, p.EntryTotalSupply.Quotient()))
+
+	valueExcludingFees := value.Divide(growth)
+	impermanentLoss := core.NewFraction(One, One).Subtract(valueExcludingFees.Divide(holdValue.Fraction).Fraction)
+
+	return &PositionValue{
+		Amount0:         amount0,
+		Amount1:         amount1,
+		Value:           value,
+		HoldValue:       holdValue,
+		FeesEarned:      value.Subtract(valueExcludingFees),
+		FeeGrowth:       core.NewPercent(big.NewInt(0).Sub(growth.Numerator, growth.Denominator), growth.Denominator),
+		ImpermanentLoss: core.NewPercent(impermanentLoss.Numerator, impermanentLoss.Denominator),
+	}, nil
+}
+
+// valueIn returns the value of the amounts of token0 and token1 in the quote token at the mid price of the pair
+func valueIn(pair *Pair, quote *core.Token, amount0, amount1 *core.CurrencyAmount) (*core.CurrencyAmount, error) {
+	quoteAmount, otherAmount := amount0, amount1
+	if quote.Equal(pair.Token1()) {
+		quoteAmount, otherAmount = amount1, amount0
+	}
+	price, err := pair.PriceOf(WrappedOf(otherAmount.Currency))
+	if err != nil {
+		return nil, err
+	}
+	quoted, err := price.Quote(otherAmount)
+	if err != nil {
+		return nil, err
+	}
+	return core.FromFractionalAmount(quote, quoted.Numerator, quoted.Denominator).Add(quoteAmount), nil
+}
@@ -0,0 +1,107 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func TestPosition(t *testing.T) {
+	million := big.NewInt(1000000)
+	entryPair, err := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(DAI, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(entryPair.LiquidityToken, million)
+	position, err := entities.NewPosition(entryPair, totalSupply, core.FromRawAmount(entryPair.LiquidityToken, big.NewInt(100000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// price moved 4x with no fees
+	{
+		pair, _ := entities.NewPair(core.FromRawAmount(DAI, big.NewInt(500000)), core.FromRawAmount(USDC, big.NewInt(2000000)), nil)
+		value, err := position.Value(pair, totalSupply, false, nil, USDC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.Amount0.Quotient().Cmp(big.NewInt(50000)) != 0 || value.Amount1.Quotient().Cmp(big.NewInt(200000)) != 0 {
+			t.Errorf("expect[50000 200000], but got[%s %s]", value.Amount0.Quotient(), value.Amount1.Quotient())
+		}
+		if value.Value.Quotient().Cmp(big.NewInt(400000)) != 0 {
+			t.Errorf("expect[400000], but got[%s]", value.Value.Quotient())
+		}
+		if value.HoldValue.Quotient().Cmp(big.NewInt(500000)) != 0 {
+			t.Errorf("expect[500000], but got[%s]", value.HoldValue.Quotient())
+		}
+		if value.ImpermanentLoss.ToFixed(2) != "20.00" {
+			t.Errorf("expect[20.00], but got[%s]", value.ImpermanentLoss.ToFixed(2))
+		}
+		if value.FeesEarned.Quotient().Cmp(entities.Zero) != 0 || value.FeeGrowth.ToFixed(2) != "0.00" {
+			t.Errorf("expect no fees, but got[%s %s]", value.FeesEarned.Quotient(), value.FeeGrowth.ToFixed(2))
+		}
+	}
+
+	// sqrt(k) grew by 10% at the same price
+	{
+		pair, _ := entities.NewPair(core.FromRawAmount(DAI, big.NewInt(1100000)), core.FromRawAmount(USDC, big.NewInt(1100000)), nil)
+		value, err := position.Value(pair, totalSupply, false, nil, DAI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.Value.Quotient().Cmp(big.NewInt(220000)) != 0 {
+			t.Errorf("expect[220000], but got[%s]", value.Value.Quotient())
+		}
+		if value.FeesEarned.Quotient().Cmp(big.NewInt(20000)) != 0 {
+			t.Errorf("expect[20000], but got[%s]", value.FeesEarned.Quotient())
+		}
+		if value.FeeGrowth.ToFixed(2) != "10.00" {
+			t.Errorf("expect[10.00], but got[%s]", value.FeeGrowth.ToFixed(2))
+		}
+		if value.ImpermanentLoss.ToFixed(2) != "0.00" {
+			t.Errorf("expect[0.00], but got[%s]", value.ImpermanentLoss.ToFixed(2))
+		}
+
+		// the protocol fee takes a sixth of the growth
+		valueFeeOn, err := position.Value(pair, totalSupply, true, big.NewInt(0).Mul(million, million), DAI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !valueFeeOn.FeesEarned.LessThan(value.FeesEarned.Fraction) {
+			t.Errorf("expect less than %s, but got[%s]", value.FeesEarned.Quotient(), valueFeeOn.FeesEarned.Quotient())
+		}
+	}
+
+	// quote token must be in the pair
+	{
+		_, err := position.Value(entryPair, totalSupply, false, nil, core.WETH9[1])
+		if !errors.Is(err, entities.ErrDiffToken) {
+			t.Errorf("expect[%+v], but got[%+v]", entities.ErrDiffToken, err)
+		}
+	}
+
+	// an empty reserve has no mid price
+	{
+		pair, _ := entities.NewPair(core.FromRawAmount(DAI, big.NewInt(0)), core.FromRawAmount(USDC, big.NewInt(5)), nil)
+		_, err := position.Value(pair, totalSupply, false, nil, USDC)
+		if !errors.Is(err, entities.ErrInsufficientReserves) {
+			t.Errorf("expect[%+v], but got[%+v]", entities.ErrInsufficientReserves, err)
+		}
+	}
+
+	// liquidity worth nothing at entry has no impermanent loss
+	{
+		pair, _ := entities.NewPair(core.FromRawAmount(DAI, big.NewInt(10)), core.FromRawAmount(USDC, big.NewInt(10)), nil)
+		dust, err := entities.NewPosition(pair, totalSupply, core.FromRawAmount(entryPair.LiquidityToken, big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = dust.Value(pair, totalSupply, false, nil, USDC)
+		if !errors.Is(err, entities.ErrInvalidEntry) {
+			t.Errorf("expect[%+v], but got[%+v]", entities.ErrInvalidEntry, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/holiman/uint256"
+)
+
+var ErrAmountOverflow = errors.New("amount exceeds 256 bits")
+
+var (
+	u997  = uint256.NewInt(997)
+	u1000 = uint256.NewInt(1000)
+)
+
+// GetAmountOut sets z to the output of the input amount given the reserves, with the math of
+// UniswapV2Library.getAmountOut and GetOutputAmount.
+// Returns ErrInsufficientReserves if a reserve is empty, ErrInsufficientInputAmount if the output is zero, and
+// ErrAmountOverflow if an intermediate product exceeds 256 bits.
+func GetAmountOut(z, amountIn, reserveIn, reserveOut *uint256.Int) error {
+	if reserveIn.IsZero() || reserveOut.IsZero() {
+		return ErrInsufficientReserves
+	}
+	var amountInWithFee, numerator, denominator uint256.Int
+	if _, overflow := amountInWithFee.MulOverflow(amountIn, u997); overflow {
+		return ErrAmountOverflow
+	}
+	if _, overflow := numerator.MulOverflow(&amountInWithFee, reserveOut); overflow {
+		return ErrAmountOverflow
+	}
+	if _, overflow := denominator.MulOverflow(reserveIn, u1000); overflow {
+		return ErrAmountOverflow
+	}
+	if _, overflow := denominator.AddOverflow(&denominator, &amountInWithFee); overflow {
+		return ErrAmountOverflow
+	}
+	z.Div(&numerator, &denominator)
+	if z.IsZero() {
+		return ErrInsufficientInputAmount
+	}
+	return nil
+}
+
+// GetAmountIn sets z to the input needed for the output amount given the reserves, with the math of
+// UniswapV2Library.getAmountIn and GetInputAmount.
+// Returns ErrInsufficientReserves if a reserve is empty or the output is not below its reserve, and
+// ErrAmountOverflow if an intermediate product exceeds 256 bits.
+func GetAmountIn(z, amountOut, reserveIn, reserveOut *uint256.Int) error {
+	if reserveIn.IsZero() || reserveOut.IsZero() || !amountOut.Lt(reserveOut) {
+		return ErrInsufficientReserves
+	}
+	var numerator, denominator uint256.Int
+	if _, overflow := numerator.MulOverflow(reserveIn, amountOut); overflow {
+		return ErrAmountOverflow
+	}
+	if _, overflow := numerator.MulOverflow(&numerator, u1000); overflow {
+		return ErrAmountOverflow
+	}
+	denominator.Sub(reserveOut, amountOut)
+	if _, overflow := denominator.MulOverflow(&denominator, u997); overflow {
+		return ErrAmountOverflow
+	}
+	z.Div(&numerator, &denominator)
+	if _, overflow := z.AddOverflow(z, uint256.NewInt(1)); overflow {
+		return ErrAmountOverflow
+	}
+	return nil
+}
+
+// setQuoteReserves caches the reserves as 256-bit integers, if they fit
+func (p *Pair) setQuoteReserves() {
+	for i, amount := range p.TokenAmounts {
+		quotient := amount.Quotient()
+		if quotient.Sign() < 0 || p.quoteReserves[i].SetFromBig(quotient) {
+			return
+		}
+	}
+	p.quoteAmounts = p.TokenAmounts
+}
+
+// reserves returns the cached reserves of the token and the other token, ok is false if there are none, e.g.
+// because TokenAmounts was replaced since NewPair
+func (p *Pair) reserves(token *entities.Token) (reserve, other *uint256.Int, ok bool) {
+	if p.quoteAmounts != p.TokenAmounts {
+		return nil, nil, false
+	}
+	if token.Equal(p.Token0()) {
+		return &p.quoteReserves[0], &p.quoteReserves[1], true
+	}
+	return &p.quoteReserves[1], &p.quoteReserves[0], true
+}
+
+// empty tells whether a reserve of the pair is zero
+func (p *Pair) empty() bool {
+	if p.quoteAmounts == p.TokenAmounts {
+		return p.quoteReserves[0].IsZero() || p.quoteReserves[1].IsZero()
+	}
+	return p.Reserve0().EqualTo(ZeroFraction) || p.Reserve1().EqualTo(ZeroFraction)
+}
+
+// reservesBig returns the reserves of the token and the other token as GetOutputAmount and GetInputAmount read them
+func (p *Pair) reservesBig(token *entities.Token) (reserve, other *big.Int) {
+	if token.Equal(p.Token0()) {
+		return p.Reserve0().Quotient(), p.Reserve1().Quotient()
+	}
+	return p.Reserve1().Quotient(), p.Reserve0().Quotient()
+}
+
+// nextReserveFits tells whether the reserve of the token plus the amount fits 256 bits, as the Pair after a swap
+// of the amount into the pair requires
+func (p *Pair) nextReserveFits(token *entities.Token, amount *uint256.Int) bool {
+	if reserve, _, ok := p.reserves(token); ok {
+		var next uint256.Int
+		_, overflow := next.AddOverflow(reserve, amount)
+		return !overflow
+	}
+	reserve, _ := p.reservesBig(token)
+	return new(big.Int).Add(reserve, amount.ToBig()).Cmp(entities.MaxUint256) <= 0
+}
+
+// QuoteOutput sets z to the output of the input amount of the token, as GetOutputAmount computes it but without
+// building the next Pair. Errors are the bare sentinels, e.g. ErrInsufficientInputAmount. The math falls back to
+// big.Int if the pair was modified after NewPair or a product exceeds 256 bits, and returns ErrAmountOverflow only
+// if the output, or the input reserve after the swap, does not fit, since no Pair could hold it.
+func (p *Pair) QuoteOutput(z *uint256.Int, tokenIn *entities.Token, amountIn *uint256.Int) error {
+	if err := p.quoteOutput(z, tokenIn, amountIn); err != nil {
+		return err
+	}
+	if !p.nextReserveFits(tokenIn, amountIn) {
+		return ErrAmountOverflow
+	}
+	return nil
+}
+
+func (p *Pair) quoteOutput(z *uint256.Int, tokenIn *entities.Token, amountIn *uint256.Int) error {
+	if !p.InvolvesToken(tokenIn) {
+		return ErrDiffToken
+	}
+	reserveIn, reserveOut, ok := p.reserves(tokenIn)
+	if ok {
+		if err := GetAmountOut(z, amountIn, reserveIn, reserveOut); !errors.Is(err, ErrAmountOverflow) {
+			return err
+		}
+	}
+	bigIn, bigOut := p.reservesBig(tokenIn)
+	if bigIn.Sign() == 0 || bigOut.Sign() == 0 {
+		return ErrInsufficientReserves
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn.ToBig(), B997)
+	numerator := new(big.Int).Mul(amountInWithFee, bigOut)
+	denominator := new(big.Int).Mul(bigIn, B1000)
+	denominator.Add(denominator, amountInWithFee)
+	amountOut := numerator.Div(numerator, denominator)
+	if amountOut.Sign() == 0 {
+		return ErrInsufficientInputAmount
+	}
+	if amountOut.Sign() < 0 || z.SetFromBig(amountOut) {
+		return ErrAmountOverflow
+	}
+	return nil
+}
+
+// QuoteInput sets z to the input of the token needed for the output amount of the token, as GetInputAmount
+// computes it but without building the next Pair. Errors are the bare sentinels, e.g. ErrInsufficientReserves.
+// The math falls back to big.Int if the pair was modified after NewPair or a product exceeds 256 bits, and
+// returns ErrAmountOverflow only if the input, or the input reserve after the swap, does not fit, since no Pair
+// could hold it.
+func (p *Pair) QuoteInput(z *uint256.Int, tokenOut *entities.Token, amountOut *uint256.Int) error {
+	if err := p.quoteInput(z, tokenOut, amountOut); err != nil {
+		return err
+	}
+	tokenIn := p.Token0()
+	if tokenIn.Equal(tokenOut) {
+		tokenIn = p.Token1()
+	}
+	if !p.nextReserveFits(tokenIn, z) {
+		return ErrAmountOverflow
+	}
+	return nil
+}
+
+func (p *Pair) quoteInput(z *uint256.Int, tokenOut *entities.Token, amountOut *uint256.Int) error {
+	if !p.InvolvesToken(tokenOut) {
+		return ErrDiffToken
+	}
+	reserveOut, reserveIn, ok := p.reserves(tokenOut)
+	if ok {
+		if err := GetAmountIn(z, amountOut, reserveIn, reserveOut); !errors.Is(err, ErrAmountOverflow) {
+			return err
+		}
+	}
+	bigOut, bigIn := p.reservesBig(tokenOut)
+	amount := amountOut.ToBig()
+	if bigIn.Sign() == 0 || bigOut.Sign() == 0 || amount.Cmp(bigOut) >= 0 {
+		return ErrInsufficientReserves
+	}
+	numerator := new(big.Int).Mul(bigIn, amount)
+	numerator.Mul(numerator, B1000)
+	denominator := new(big.Int).Sub(bigOut, amount)
+	denominator.Mul(denominator, B997)
+	amountIn := numerator.Div(numerator, denominator)
+	amountIn.Add(amountIn, One)
+	if amountIn.Sign() < 0 || z.SetFromBig(amountIn) {
+		return ErrAmountOverflow
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func TestQuoteMatchesPairMath(t *testing.T) {
+	reserves := [][2]int64{{1000, 1000}, {1000, 1100}, {7, 1e18}, {1e18, 3}, {123456789, 987654321}}
+	amounts := []int64{1, 2, 10, 99, 1000, 999999, 1e17}
+	for _, r := range reserves {
+		pair, err := entities.NewPair(core.FromRawAmount(USDC, big.NewInt(r[0])), core.FromRawAmount(DAI, big.NewInt(r[1])), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, token := range []*core.Token{USDC, DAI} {
+			for _, amount := range amounts {
+				var quoted uint256.Int
+				errQuote := pair.QuoteOutput(&quoted, token, uint256.NewInt(uint64(amount)))
+				output, _, err := pair.GetOutputAmount(core.FromRawAmount(token, big.NewInt(amount)))
+				if !errors.Is(err, errQuote) && !(err == nil && errQuote == nil) {
+					t.Fatalf("expect[%v], but got[%v]", err, errQuote)
+				}
+				if err == nil && output.Quotient().Cmp(quoted.ToBig()) != 0 {
+					t.Errorf("expect[%s], but got[%s]", output.Quotient(), quoted.ToBig())
+				}
+
+				errQuote = pair.QuoteInput(&quoted, token, uint256.NewInt(uint64(amount)))
+				input, _, err := pair.GetInputAmount(core.FromRawAmount(token, big.NewInt(amount)))
+				if !errors.Is(err, errQuote) && !(err == nil && errQuote == nil) {
+					t.Fatalf("expect[%v], but got[%v]", err, errQuote)
+				}
+				if err == nil && input.Quotient().Cmp(quoted.ToBig()) != 0 {
+					t.Errorf("expect[%s], but got[%s]", input.Quotient(), quoted.ToBig())
+				}
+			}
+		}
+	}
+}
+
+func TestQuoteFallback(t *testing.T) {
+	pair, err := entities.NewPair(core.FromRawAmount(USDC, big.NewInt(1000)), core.FromRawAmount(DAI, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quoted uint256.Int
+	if err := pair.QuoteOutput(&quoted, core.WETH9[1], uint256.NewInt(1)); !errors.Is(err, entities.ErrDiffToken) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrDiffToken, err)
+	}
+
+	// reserves replaced after NewPair are quoted with the pair math
+	pair.TokenAmounts[0] = core.FromRawAmount(pair.Token0(), big.NewInt(2000))
+	if err := pair.QuoteOutput(&quoted, pair.Token0(), uint256.NewInt(100)); err != nil || quoted.Uint64() != 47 {
+		t.Errorf("expect[47], but got[%d, %v]", quoted.Uint64(), err)
+	}
+
+	// products beyond 256 bits
+	max := new(uint256.Int).SetAllOne()
+	if err := entities.GetAmountOut(&quoted, max, uint256.NewInt(1000), uint256.NewInt(1000)); !errors.Is(err, entities.ErrAmountOverflow) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrAmountOverflow, err)
+	}
+	huge := new(big.Int).Lsh(big.NewInt(1), 250)
+	pair, err = entities.NewPair(core.FromRawAmount(USDC, huge), core.FromRawAmount(DAI, huge), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amountIn := core.FromRawAmount(pair.Token0(), new(big.Int).Rsh(huge, 1))
+	output, _, err := pair.GetOutputAmount(amountIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, _ := uint256.FromBig(amountIn.Quotient())
+	if err := pair.QuoteOutput(&quoted, pair.Token0(), amount); err != nil || quoted.ToBig().Cmp(output.Quotient()) != 0 {
+		t.Errorf("expect[%s], but got[%s, %v]", output.Quotient(), quoted.ToBig(), err)
+	}
+}
+
+// baselineBestTradeExactIn is the best trade search building a Pair and a Trade for every hop, as before quoting
+// on 256-bit integers
+func baselineBestTradeExactIn(pairs []*entities.Pair, currencyAmountIn *core.CurrencyAmount, currencyOut core.Currency, maxHops int, currentPairs []*entities.Pair, nextAmountIn *core.CurrencyAmount, bestTrades []*entities.Trade) ([]*entities.Trade, error) {
+	amountIn, tokenOut := nextAmountIn.Wrapped(), currencyOut.Wrapped()
+	for i, pair := range pairs {
+		if !pair.InvolvesToken(amountIn.Currency.Wrapped()) || pair.Reserve0().EqualTo(entities.ZeroFraction) || pair.Reserve1().EqualTo(entities.ZeroFraction) {
+			continue
+		}
+		amountOut, _, err := pair.GetOutputAmount(amountIn)
+		if err != nil {
+			if errors.Is(err, entities.ErrInsufficientInputAmount) {
+				continue
+			}
+			return nil, err
+		}
+		path := append(append([]*entities.Pair{}, currentPairs...), pair)
+		if amountOut.Currency.Equal(tokenOut) {
+			route, err := entities.NewRoute(path, currencyAmountIn.Currency, currencyOut)
+			if err != nil {
+				return nil, err
+			}
+			trade, err := entities.NewTrade(route, currencyAmountIn, entities.ExactInput)
+			if err != nil {
+				return nil, err
+			}
+			if bestTrades, _, err = entities.SortedInsert(bestTrades, trade, 3, entities.TradeComparator); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if maxHops > 1 && len(pairs) > 1 {
+			rest := append(append([]*entities.Pair{}, pairs[:i]...), pairs[i+1:]...)
+			if bestTrades, err = baselineBestTradeExactIn(rest, currencyAmountIn, currencyOut, maxHops-1, path, amountOut, bestTrades); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bestTrades, nil
+}
+
+// meshPairs returns a pair between every two of count tokens, with uneven reserves
+func meshPairs(count int) ([]*core.Token, []*entities.Pair) {
+	tokens := make([]*core.Token, count)
+	for i := range tokens {
+		tokens[i] = core.NewToken(1, common.BigToAddress(big.NewInt(int64(i+1))), 18, "", "")
+	}
+	var pairs []*entities.Pair
+	for i := range tokens {
+		for j := i + 1; j < len(tokens); j++ {
+			pair, err := entities.NewPair(
+				core.FromRawAmount(tokens[i], big.NewInt(int64(1e9+i*7919+j*104729))),
+				core.FromRawAmount(tokens[j], big.NewInt(int64(1e9+j*7919+i*104729))),
+				nil,
+			)
+			if err != nil {
+				panic(err)
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return tokens, pairs
+}
+
+func TestBestTradeMatchesBaseline(t *testing.T) {
+	tokens, pairs := meshPairs(6)
+	for _, amount := range []int64{1, 1000, 1e6, 1e9} {
+		amountIn := core.FromRawAmount(tokens[0], big.NewInt(amount))
+		expect, err := baselineBestTradeExactIn(pairs, amountIn, tokens[5], 3, nil, amountIn, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, err := entities.BestTradeExactIn(pairs, amountIn, tokens[5], nil, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expect) != len(output) {
+			t.Fatalf("expect[%d], but got[%d]", len(expect), len(output))
+		}
+		for i := range expect {
+			if expect[i].OutputAmount().Quotient().Cmp(output[i].OutputAmount().Quotient()) != 0 || len(expect[i].Route.Pairs) != len(output[i].Route.Pairs) {
+				t.Errorf("expect[%s through %d pairs], but got[%s through %d pairs]", expect[i].OutputAmount().Quotient(), len(expect[i].Route.Pairs), output[i].OutputAmount().Quotient(), len(output[i].Route.Pairs))
+			}
+		}
+	}
+}
+
+func TestBestTradeSkipsOverflow(t *testing.T) {
+	// the input of 999 DAI from this pair exceeds 256 bits
+	deep, err := entities.NewPair(core.FromRawAmount(USDC, new(big.Int).Lsh(big.NewInt(1), 255)), core.FromRawAmount(DAI, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := entities.NewPair(core.FromRawAmount(USDC, big.NewInt(1e6)), core.FromRawAmount(DAI, big.NewInt(1e6)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err := entities.BestTradeExactOut([]*entities.Pair{deep, pair}, USDC, core.FromRawAmount(DAI, big.NewInt(999)), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].Route.Pairs[0] != pair || trades[0].InputAmount().Quotient().Int64() != 1004 {
+		t.Errorf("expect[1 trade of 1004 through the shallow pair], but got[%v]", trades)
+	}
+
+	// the input reserve after the swap exceeds 256 bits, although the output fits
+	amountIn := core.FromRawAmount(USDC, new(big.Int).Sub(core.MaxUint256, big.NewInt(10)))
+	trades, err = entities.BestTradeExactIn([]*entities.Pair{pair}, amountIn, DAI, nil, nil, nil, nil)
+	if err != nil || len(trades) != 0 {
+		t.Errorf("expect[no trades], but got[%d, %v]", len(trades), err)
+	}
+	full, err := entities.NewPair(core.FromRawAmount(USDC, new(big.Int).Sub(core.MaxUint256, big.NewInt(10))), core.FromRawAmount(DAI, core.MaxUint256), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err = entities.BestTradeExactIn([]*entities.Pair{full, pair}, core.FromRawAmount(USDC, big.NewInt(1000)), DAI, nil, nil, nil, nil)
+	if err != nil || len(trades) != 1 || trades[0].Route.Pairs[0] != pair {
+		t.Errorf("expect[1 trade through the shallow pair], but got[%d, %v]", len(trades), err)
+	}
+	var quoted uint256.Int
+	if err := full.QuoteInput(&quoted, DAI, uint256.NewInt(1000)); !errors.Is(err, entities.ErrAmountOverflow) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrAmountOverflow, err)
+	}
+}
+
+func BenchmarkBestTradeExactIn(b *testing.B) {
+	tokens, pairs := meshPairs(6)
+	amountIn := core.FromRawAmount(tokens[0], big.NewInt(1e6))
+	b.Run("quote", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := entities.BestTradeExactIn(pairs, amountIn, tokens[5], nil, nil, nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("baseline", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := baselineBestTradeExactIn(pairs, amountIn, tokens[5], 3, nil, amountIn, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package entities
+
+import "fmt"
+
+// Ranked keeps the best items seen so far, at most MaxSize of them, sorted by a comparator so the best comes first.
+// It ranks trades as BestTradeExactIn/Out do, and any other type with input and output amounts.
+type Ranked[T InputOutput] struct {
+	maxSize    int
+	comparator func(a, b T) (int, error)
+	items      []T
+}
+
+// NewRanked creates an empty container of at most maxSize items
+// @param maxSize how many items to keep
+// @param comparator orders the items, negative if a comes before b, e.g. CompareInputOutput
+func NewRanked[T InputOutput](maxSize int, comparator func(a, b T) (int, error)) (*Ranked[T], error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrMaxSizeZero, maxSize)
+	}
+	return &Ranked[T]{maxSize: maxSize, comparator: comparator}, nil
+}
+
+// CompareInputOutput is InputOutputComparator for a concrete type
+func CompareInputOutput[T InputOutput](a, b T) (int, error) {
+	return InputOutputComparator(a, b)
+}
+
+// Insert adds the item at its rank. Returns the item that no longer fits, if any, which may be the item itself.
+// The items are unchanged if the comparator fails.
+func (r *Ranked[T]) Insert(item T) (pop T, popped bool, err error) {
+	items, pop, popped, err := sortedInsert(r.items, item, r.maxSize, r.comparator)
+	if err != nil {
+		return pop, false, err
+	}
+	r.items = items
+	return pop, popped, nil
+}
+
+// Items returns the items, best first. The slice must not be modified.
+func (r *Ranked[T]) Items() []T {
+	return r.items
+}
+
+// Len returns the number of items
+func (r *Ranked[T]) Len() int {
+	return len(r.items)
+}
+
+// MaxSize returns the most items the container keeps
+func (r *Ranked[T]) MaxSize() int {
+	return r.maxSize
+}
+
+// sortedInsert inserts an item into its sort index of the items sorted by `comparator`, and constrains the size to
+// `maxSize` by removing the last item
+func sortedInsert[T InputOutput](items []T, add T, maxSize int, comparator func(a, b T) (int, error)) (sortedItems []T, pop T, popped bool, err error) {
+	if maxSize <= 0 {
+		return nil, pop, false, fmt.Errorf("%w: %d", ErrMaxSizeZero, maxSize)
+	}
+	itemsLen := len(items)
+	// this is an invariant because the interface cannot return multiple removed items if items.length exceeds maxSize
+	if itemsLen > maxSize {
+		return nil, pop, false, fmt.Errorf("%w: %d > %d", ErrItemsSize, itemsLen, maxSize)
+	}
+
+	// short circuit first item add
+	if itemsLen == 0 {
+		return append(items, add), pop, false, nil
+	}
+
+	isFull := itemsLen == maxSize
+	// short circuit if full and the additional item does not come before the last item
+	if isFull {
+		comp, err := comparator(items[itemsLen-1], add)
+		if err != nil {
+			return nil, pop, false, err
+		}
+		if comp <= 0 {
+			return items, add, true, nil
+		}
+	}
+
+	lo, hi := 0, itemsLen
+	for lo < hi {
+		mid := (hi-lo)/2 + lo
+		comp, err := comparator(items[mid], add)
+		if err != nil {
+			return nil, pop, false, err
+		}
+		if comp <= 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	items = append(items[:lo], append([]T{add}, items[lo:]...)...)
+	if isFull {
+		pop, popped = items[itemsLen], true
+		items = items[:itemsLen]
+	}
+	return items, pop, popped, nil
+}
@@ -0,0 +1,105 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+// quote is a caller defined candidate, e.g. a price from another venue
+type quote struct {
+	venue  string
+	input  *core.CurrencyAmount
+	output *core.CurrencyAmount
+}
+
+func (q *quote) InputAmount() *core.CurrencyAmount {
+	return q.input
+}
+
+func (q *quote) OutputAmount() *core.CurrencyAmount {
+	return q.output
+}
+
+func TestRanked(t *testing.T) {
+	token0 := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000001"), 18, "t0", "")
+	token1 := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000002"), 18, "t1", "")
+	newQuote := func(venue string, in, out int64) *quote {
+		return &quote{
+			venue:  venue,
+			input:  core.FromRawAmount(token0, big.NewInt(in)),
+			output: core.FromRawAmount(token1, big.NewInt(out)),
+		}
+	}
+
+	if _, err := entities.NewRanked(0, entities.CompareInputOutput[*quote]); !errors.Is(err, entities.ErrMaxSizeZero) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrMaxSizeZero, err)
+	}
+
+	ranked, err := entities.NewRanked(2, entities.CompareInputOutput[*quote])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, popped, err := ranked.Insert(newQuote("a", 100, 90)); err != nil || popped {
+		t.Fatalf("expect[nothing popped], but got[%v %v]", popped, err)
+	}
+	if _, popped, err := ranked.Insert(newQuote("b", 100, 95)); err != nil || popped {
+		t.Fatalf("expect[nothing popped], but got[%v %v]", popped, err)
+	}
+	// the worst quote is popped once full
+	pop, popped, err := ranked.Insert(newQuote("c", 90, 90))
+	if err != nil || !popped || pop.venue != "a" {
+		t.Fatalf("expect[a popped], but got[%v %v %v]", pop, popped, err)
+	}
+	// a quote worse than all is popped itself
+	pop, popped, err = ranked.Insert(newQuote("d", 100, 80))
+	if err != nil || !popped || pop.venue != "d" {
+		t.Fatalf("expect[d popped], but got[%v %v %v]", pop, popped, err)
+	}
+	if ranked.Len() != 2 || ranked.MaxSize() != 2 {
+		t.Errorf("expect[2 2], but got[%d %d]", ranked.Len(), ranked.MaxSize())
+	}
+	var venues string
+	for _, q := range ranked.Items() {
+		venues += q.venue
+	}
+	if venues != "bc" {
+		t.Errorf("expect[bc], but got[%s]", venues)
+	}
+
+	// comparator errors are returned and leave the items as they were
+	mismatch := &quote{
+		venue:  "e",
+		input:  core.FromRawAmount(token1, big.NewInt(100)),
+		output: core.FromRawAmount(token0, big.NewInt(100)),
+	}
+	if _, _, err := ranked.Insert(mismatch); !errors.Is(err, entities.ErrInvalidCurrency) {
+		t.Errorf("expect[%v], but got[%v]", entities.ErrInvalidCurrency, err)
+	}
+	if ranked.Len() != 2 {
+		t.Errorf("expect[2], but got[%d]", ranked.Len())
+	}
+
+	// trades rank the same way as in BestTradeExactIn
+	pair_0_1, _ := entities.NewPair(
+		core.FromRawAmount(token0, big.NewInt(1000)),
+		core.FromRawAmount(token1, big.NewInt(1000)),
+		nil,
+	)
+	trades, err := entities.NewRanked(1, entities.TradeComparator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, _ := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	small, _ := entities.NewTrade(route, core.FromRawAmount(token0, big.NewInt(10)), entities.ExactInput)
+	large, _ := entities.NewTrade(route, core.FromRawAmount(token0, big.NewInt(100)), entities.ExactInput)
+	trades.Insert(small)
+	pop2, popped, err := trades.Insert(large)
+	if err != nil || !popped || pop2 != small {
+		t.Errorf("expect[small popped], but got[%v %v]", popped, err)
+	}
+}
@@ -13,12 +13,14 @@ var (
 	ErrInvalidPath          = fmt.Errorf("invalid pairs for path")
 )
 
+// Route is a path through pairs. A native input or output trades as its wrapped token of NativeCurrencies.
 type Route struct {
 	Pairs    []*Pair
 	Path     []*core.Token
 	Input    core.Currency
 	Output   core.Currency
 	midPrice *core.Price
+	curve    *Curve
 }
 
 func NewRoute(pairs []*Pair, input, output core.Currency) (*Route, error) {
@@ -32,15 +34,25 @@ func NewRoute(pairs []*Pair, input, output core.Currency) (*Route, error) {
 		}
 	}
 
-	if !pairs[0].InvolvesToken(input.Wrapped()) {
+	tokenIn, err := ResolveWrapped(input)
+	if err != nil {
+		return nil, err
+	}
+	if !pairs[0].InvolvesToken(tokenIn) {
 		return nil, ErrInvalidInput
 	}
-	if !(output == nil || pairs[len(pairs)-1].InvolvesToken(output.Wrapped())) {
-		return nil, ErrInvalidOutput
+	if output != nil {
+		tokenOut, err := ResolveWrapped(output)
+		if err != nil {
+			return nil, err
+		}
+		if !pairs[len(pairs)-1].InvolvesToken(tokenOut) {
+			return nil, ErrInvalidOutput
+		}
 	}
 
 	path := make([]*core.Token, len(pairs)+1)
-	path[0] = input.Wrapped()
+	path[0] = tokenIn
 	for i := range pairs {
 		currentInput := path[i]
 		if !(currentInput.Equal(pairs[i].Token0()) || currentInput.Equal(pairs[i].Token1())) {
@@ -0,0 +1,230 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+var (
+	ErrInvalidDrift = errors.New("invalid price drift")
+
+	// maxReserve is the largest reserve a pair can hold, reserves are stored as uint112
+	maxReserve = big.NewInt(0).Sub(big.NewInt(0).Lsh(One, 112), One)
+)
+
+// SandwichHop is the exposure of a trade to a sandwich attack on one of the pairs of its route.
+// Amounts of the attack are in the input token of the hop.
+type SandwichHop struct {
+	Index         int                  // Index of the pair in the route
+	Pair          *Pair                // The attacked pair
+	FrontRun      *core.CurrencyAmount // The largest amount the attacker can swap before the trade without making it revert
+	BackRunOutput *core.CurrencyAmount // The amount the attacker receives swapping the front run output back after the trade
+	Profit        *core.CurrencyAmount // BackRunOutput less FrontRun
+	ProfitInInput *core.CurrencyAmount // Profit valued in the trade input currency at the route mid price
+}
+
+// SandwichEstimate is the exposure of a trade to sandwich attacks
+type SandwichEstimate struct {
+	Hops []*SandwichHop // Exposure of every pair of the route
+	Best *SandwichHop   // The hop with the largest profit in the trade input currency
+}
+
+// SandwichExposure estimates the profit an attacker can extract by front running and back running the trade
+// on one of its pairs, within the bounds of the slippage tolerance. For each pair it finds the largest front run
+// after which the trade still succeeds, which for a constant product pair is also the most profitable one.
+// @param slippageTolerance the slippage tolerance the trade is submitted with
+func (t *Trade) SandwichExposure(slippageTolerance *core.Percent) (*SandwichEstimate, error) {
+	minAmountOut, err := t.MinimumAmountOut(slippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+	maxAmountIn, err := t.MaximumAmountIn(slippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &SandwichEstimate{}
+	for i := range t.Route.Pairs {
+		hop, err := t.sandwichHop(i, minAmountOut.Quotient(), maxAmountIn.Quotient())
+		if err != nil {
+			return nil, err
+		}
+		estimate.Hops = append(estimate.Hops, hop)
+		if estimate.Best == nil || hop.ProfitInInput.GreaterThan(estimate.Best.ProfitInInput.Fraction) {
+			estimate.Best = hop
+		}
+	}
+	return estimate, nil
+}
+
+// sandwichHop finds the largest front run on the i-th pair of the route that lets the trade succeed
+func (t *Trade) sandwichHop(i int, minAmountOut, maxAmountIn *big.Int) (*SandwichHop, error) {
+	pair, token := t.Route.Pairs[i], t.Route.Path[i]
+	reserveIn, err := pair.ReserveOf(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// exponential then binary search of the largest amount for which the trade succeeds
+	limit := big.NewInt(0).Sub(maxReserve, reserveIn.Quotient())
+	if limit.Sign() < 0 {
+		// the pair already holds more than a reserve can, no front run fits
+		limit.Set(Zero)
+	}
+	lo, hi := big.NewInt(0), big.NewInt(1)
+	for {
+		if hi.Cmp(limit) > 0 {
+			hi.Set(limit)
+		}
+		result, err := t.sandwich(i, hi, minAmountOut, maxAmountIn)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			break
+		}
+		lo.Set(hi)
+		if hi.Cmp(limit) == 0 {
+			break
+		}
+		hi.Lsh(hi, 1)
+	}
+	for big.NewInt(0).Sub(hi, lo).Cmp(One) > 0 {
+		mid := big.NewInt(0).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		result, err := t.sandwich(i, mid, minAmountOut, maxAmountIn)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	hop := &SandwichHop{
+		Index:         i,
+		Pair:          pair,
+		FrontRun:      core.FromRawAmount(token, lo),
+		BackRunOutput: core.FromRawAmount(token, big.NewInt(0)),
+	}
+	if lo.Cmp(Zero) > 0 {
+		if hop.BackRunOutput, err = t.sandwich(i, lo, minAmountOut, maxAmountIn); err != nil {
+			return nil, err
+		}
+	}
+	hop.Profit = core.FromRawAmount(token, big.NewInt(0).Sub(hop.BackRunOutput.Quotient(), lo))
+
+	hop.ProfitInInput = core.FromRawAmount(t.Route.Input, hop.Profit.Quotient())
+	if i > 0 {
+		prefix, err := NewRoute(t.Route.Pairs[:i], t.Route.Input, token)
+		if err != nil {
+			return nil, err
+		}
+		midPrice, err := prefix.MidPrice()
+		if err != nil {
+			return nil, err
+		}
+		profit := midPrice.Invert().Fraction.Multiply(hop.Profit.Fraction)
+		hop.ProfitInInput = core.FromFractionalAmount(t.Route.Input, profit.Numerator, profit.Denominator)
+	}
+	return hop, nil
+}
+
+// sandwich front runs the i-th pair with amountIn, executes the trade and back runs the pair.
+// Returns the back run output, or nil if the trade reverts.
+func (t *Trade) sandwich(i int, amountIn, minAmountOut, maxAmountIn *big.Int) (*core.CurrencyAmount, error) {
+	frontRunOutput, attackedPair, err := t.Route.Pairs[i].GetOutputAmount(core.FromRawAmount(t.Route.Path[i], amountIn))
+	if errors.Is(err, ErrInsufficientInputAmount) {
+		// nothing to back run, the trade only gets better
+		return core.FromRawAmount(t.Route.Path[i], big.NewInt(0)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]*Pair, len(t.Route.Pairs))
+	copy(pairs, t.Route.Pairs)
+	pairs[i] = attackedPair
+	var victimPair *Pair
+	if t.TradeType == ExactInput {
+		amount := WrappedAmount(t.inputAmount)
+		for j := range pairs {
+			var nextPair *Pair
+			amount, nextPair, err = pairs[j].GetOutputAmount(amount)
+			if reverts(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if j == i {
+				victimPair = nextPair
+			}
+		}
+		if amount.Quotient().Cmp(minAmountOut) < 0 {
+			return nil, nil
+		}
+	} else {
+		amount := WrappedAmount(t.outputAmount)
+		for j := len(pairs) - 1; j >= 0; j-- {
+			var nextPair *Pair
+			amount, nextPair, err = pairs[j].GetInputAmount(amount)
+			if reverts(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if j == i {
+				victimPair = nextPair
+			}
+		}
+		if amount.Quotient().Cmp(maxAmountIn) > 0 {
+			return nil, nil
+		}
+	}
+
+	backRunOutput, _, err := victimPair.GetOutputAmount(frontRunOutput)
+	if errors.Is(err, ErrInsufficientInputAmount) {
+		return core.FromRawAmount(t.Route.Path[i], big.NewInt(0)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return backRunOutput, nil
+}
+
+// reverts tells whether the error of a hop of the trade is one the pair reverts the swap with
+func reverts(err error) bool {
+	return errors.Is(err, ErrInsufficientReserves) || errors.Is(err, ErrInsufficientInputAmount)
+}
+
+// RecommendSlippage returns the tightest slippage tolerance with which the trade still succeeds if its execution
+// price moves unfavorably by drift before it is mined. Any wider tolerance is exposed to sandwich attacks for
+// no benefit, see SandwichExposure.
+// @param drift expected unfavorable move of the execution price, from 0 to 1 exclusive
+func (t *Trade) RecommendSlippage(drift *core.Percent) (*core.Percent, error) {
+	if drift.LessThan(ZeroFraction) || !drift.LessThan(core.NewFraction(One, One)) {
+		return nil, ErrInvalidDrift
+	}
+
+	remaining := core.NewFraction(One, One).Subtract(drift.Fraction)
+	if t.TradeType == ExactInput {
+		// outputAmount / (1 + slippage) = outputAmount * (1 - drift)
+		slippage := drift.Fraction.Divide(remaining)
+		return core.NewPercent(slippage.Numerator, slippage.Denominator), nil
+	}
+
+	// inputAmount * (1 + slippage) = ceil(inputAmount / (1 - drift))
+	amountIn := t.inputAmount.Quotient()
+	driftedIn := core.NewFraction(amountIn, One).Divide(remaining)
+	maxIn := driftedIn.Quotient()
+	if driftedIn.Remainder().Numerator.Cmp(Zero) != 0 {
+		maxIn.Add(maxIn, One)
+	}
+	return core.NewPercent(big.NewInt(0).Sub(maxIn, amountIn), amountIn), nil
+}
@@ -0,0 +1,158 @@
+package entities_test
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func TestSandwichExposure(t *testing.T) {
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(DAI, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, err := entities.NewRoute([]*entities.Pair{pair}, DAI, USDC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(DAI, big.NewInt(10000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slippage := core.NewPercent(big.NewInt(1), B100)
+	minAmountOut, _ := trade.MinimumAmountOut(slippage)
+
+	estimate, err := trade.SandwichExposure(slippage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(estimate.Hops) != 1 || estimate.Best != estimate.Hops[0] {
+		t.Fatalf("wrong hops %+v", estimate.Hops)
+	}
+	hop := estimate.Best
+	if !hop.Profit.GreaterThan(entities.ZeroFraction) {
+		t.Errorf("expect profit, but got[%s]", hop.Profit.Quotient())
+	}
+	if !hop.ProfitInInput.EqualTo(hop.Profit.Fraction) {
+		t.Errorf("expect[%s], but got[%s]", hop.Profit.Quotient(), hop.ProfitInInput.Quotient())
+	}
+
+	// the front run is the largest the trade tolerates
+	victimOutput := func(frontRun *big.Int) *big.Int {
+		_, attacked, err := pair.GetOutputAmount(core.FromRawAmount(DAI, frontRun))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, _, err := attacked.GetOutputAmount(trade.InputAmount())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output.Quotient()
+	}
+	if victimOutput(hop.FrontRun.Quotient()).Cmp(minAmountOut.Quotient()) < 0 {
+		t.Error("trade reverts after the front run")
+	}
+	if victimOutput(big.NewInt(0).Add(hop.FrontRun.Quotient(), entities.One)).Cmp(minAmountOut.Quotient()) >= 0 {
+		t.Error("a larger front run is possible")
+	}
+
+	// no room without slippage
+	{
+		estimate, err := trade.SandwichExposure(core.NewPercent(big.NewInt(0), B100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if estimate.Best.Profit.GreaterThan(entities.ZeroFraction) {
+			t.Errorf("expect no profit, but got[%s]", estimate.Best.Profit.Quotient())
+		}
+	}
+
+	// exact out trades through several pairs
+	{
+		pair2, _ := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(core.WETH9[1], million), nil)
+		route, _ := entities.NewRoute([]*entities.Pair{pair, pair2}, DAI, core.WETH9[1])
+		trade, err := entities.ExactOut(route, core.FromRawAmount(core.WETH9[1], big.NewInt(10000)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		estimate, err := trade.SandwichExposure(slippage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(estimate.Hops) != 2 {
+			t.Fatalf("expect[2], but got[%d]", len(estimate.Hops))
+		}
+		for _, hop := range estimate.Hops {
+			if !hop.Profit.GreaterThan(entities.ZeroFraction) || !hop.ProfitInInput.Currency.Equal(DAI) {
+				t.Errorf("hop %d: expect profit in DAI, but got[%s %s]", hop.Index, hop.ProfitInInput.Quotient(), hop.ProfitInInput.Currency.Symbol())
+			}
+		}
+	}
+
+	// a reserve beyond uint112 leaves no room for a front run
+	{
+		deep := new(big.Int).Lsh(big.NewInt(1), 120)
+		pair, _ := entities.NewPair(core.FromRawAmount(USDC, deep), core.FromRawAmount(DAI, deep), nil)
+		route, _ := entities.NewRoute([]*entities.Pair{pair}, DAI, USDC)
+		trade, err := entities.ExactIn(route, core.FromRawAmount(DAI, million))
+		if err != nil {
+			t.Fatal(err)
+		}
+		estimate, err := trade.SandwichExposure(slippage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if estimate.Best.FrontRun.Quotient().Sign() != 0 || estimate.Best.Profit.Quotient().Sign() != 0 {
+			t.Errorf("expect[0 0], but got[%s %s]", estimate.Best.FrontRun.Quotient(), estimate.Best.Profit.Quotient())
+		}
+	}
+}
+
+func TestRecommendSlippage(t *testing.T) {
+	million := big.NewInt(1000000)
+	pair, _ := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(DAI, million), nil)
+	route, _ := entities.NewRoute([]*entities.Pair{pair}, DAI, USDC)
+	drift := core.NewPercent(big.NewInt(1), B100)
+
+	{
+		trade, _ := entities.ExactIn(route, core.FromRawAmount(DAI, big.NewInt(10000)))
+		slippage, err := trade.RecommendSlippage(drift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 1 / 99
+		if slippage.ToSignificant(4) != "1.01" {
+			t.Errorf("expect[1.01], but got[%s]", slippage.ToSignificant(4))
+		}
+		minAmountOut, _ := trade.MinimumAmountOut(slippage)
+		drifted := big.NewInt(0).Mul(trade.OutputAmount().Quotient(), big.NewInt(99))
+		drifted.Div(drifted, B100)
+		if minAmountOut.Quotient().Cmp(drifted) != 0 {
+			t.Errorf("expect[%s], but got[%s]", drifted, minAmountOut.Quotient())
+		}
+	}
+
+	{
+		trade, _ := entities.ExactOut(route, core.FromRawAmount(USDC, big.NewInt(10000)))
+		slippage, err := trade.RecommendSlippage(drift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		maxAmountIn, _ := trade.MaximumAmountIn(slippage)
+		// ceil(10132 / 0.99)
+		if maxAmountIn.Quotient().Cmp(big.NewInt(10235)) != 0 {
+			t.Errorf("expect[10235], but got[%s]", maxAmountIn.Quotient())
+		}
+	}
+
+	{
+		trade, _ := entities.ExactIn(route, core.FromRawAmount(DAI, big.NewInt(10000)))
+		_, err := trade.RecommendSlippage(core.NewPercent(B100, B100))
+		if err != entities.ErrInvalidDrift {
+			t.Errorf("expect[%+v], but got[%+v]", entities.ErrInvalidDrift, err)
+		}
+	}
+}
@@ -78,7 +78,7 @@ func NewTrade(route *Route, amount *core.CurrencyAmount, tradeType TradeType) (*
 	var inputAmount, outputAmount *core.CurrencyAmount
 	if tradeType == ExactInput {
 		if !amount.Currency.Equal(route.Input) {
-			return nil, ErrInvalidCurrency
+			return nil, &CurrencyError{Expected: route.Input, Actual: amount.Currency}
 		}
 		amounts[0] = amount
 		for i := 0; i < len(route.Path)-1; i++ {
@@ -93,7 +93,7 @@ func NewTrade(route *Route, amount *core.CurrencyAmount, tradeType TradeType) (*
 		outputAmount = core.FromFractionalAmount(route.Output, amounts[len(amounts)-1].Numerator, amounts[len(amounts)-1].Denominator)
 	} else {
 		if !amount.Currency.Equal(route.Output) {
-			return nil, ErrInvalidCurrency
+			return nil, &CurrencyError{Expected: route.Output, Actual: amount.Currency}
 		}
 		amounts[len(amounts)-1] = amount
 		for i := len(route.Path) - 1; i > 0; i-- {
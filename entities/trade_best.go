@@ -1,15 +1,31 @@
 package entities
 
 import (
+	"errors"
 	"fmt"
+
 	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/holiman/uint256"
 )
 
 var (
+	ErrMaxSizeZero      = fmt.Errorf("max size must be positive")
+	ErrItemsSize        = fmt.Errorf("items exceed max size")
 	ErrInvalidOption    = fmt.Errorf("invalid maxHops")
 	ErrInvalidRecursion = fmt.Errorf("invalid recursion")
+	ErrWrap             = fmt.Errorf("currencies are wrapped and unwrapped 1:1, not traded")
 )
 
+// IsWrap returns true if the currencies are the native currency and its wrapped token, in either order.
+// Such a conversion is a WETH deposit or withdrawal rather than a trade through pairs.
+func IsWrap(currencyIn, currencyOut entities.Currency) bool {
+	if currencyIn.IsNative() == currencyOut.IsNative() {
+		return false
+	}
+	wrappedIn, wrappedOut := WrappedOf(currencyIn), WrappedOf(currencyOut)
+	return wrappedIn != nil && wrappedOut != nil && wrappedIn.Equal(wrappedOut)
+}
+
 type BestTradeOptions struct {
 	// how many results to return
 	MaxNumResults int
@@ -38,91 +54,311 @@ type InputOutput interface {
 }
 
 // comparator function that allows sorting trades by their output amounts, in decreasing order, and then input amounts
-// in increasing order. i.e. the best trades have the most outputs for the least inputs and are sorted first
-func InputOutputComparator(a, b InputOutput) int {
+// in increasing order. i.e. the best trades have the most outputs for the least inputs and are sorted first.
+// Returns a *CurrencyError if the trades are not between the same currencies.
+func InputOutputComparator(a, b InputOutput) (int, error) {
 	// must have same input and output token for comparison
-	if !a.InputAmount().Currency.Equal(b.InputAmount().Currency) ||
-		!a.OutputAmount().Currency.Equal(b.OutputAmount().Currency) {
-		// TODO: better error handling
-		panic(ErrInvalidCurrency)
+	if !a.InputAmount().Currency.Equal(b.InputAmount().Currency) {
+		return 0, &CurrencyError{Expected: a.InputAmount().Currency, Actual: b.InputAmount().Currency}
+	}
+	if !a.OutputAmount().Currency.Equal(b.OutputAmount().Currency) {
+		return 0, &CurrencyError{Expected: a.OutputAmount().Currency, Actual: b.OutputAmount().Currency}
 	}
 
 	if a.OutputAmount().EqualTo(b.OutputAmount().Fraction) {
 		if a.InputAmount().EqualTo(b.InputAmount().Fraction) {
-			return 0
+			return 0, nil
 		}
 		// trade A requires less input than trade B, so A should come first
 		if a.InputAmount().LessThan(b.InputAmount().Fraction) {
-			return -1
+			return -1, nil
 		}
-		return 1
+		return 1, nil
 	}
 
 	// tradeA has less output than trade B, so should come second
 	if a.OutputAmount().LessThan(b.OutputAmount().Fraction) {
-		return 1
+		return 1, nil
 	}
-	return -1
+	return -1, nil
 }
 
 // extension of the input output comparator that also considers other dimensions of the trade in ranking them
-func TradeComparator(a, b *Trade) int {
-	ioComp := InputOutputComparator(a, b)
-	if ioComp != 0 {
-		return ioComp
+func TradeComparator(a, b *Trade) (int, error) {
+	ioComp, err := InputOutputComparator(a, b)
+	if err != nil || ioComp != 0 {
+		return ioComp, err
 	}
 
 	// consider lowest slippage next, since these are less likely to fail
 	if a.PriceImpact.LessThan(b.PriceImpact.Fraction) {
-		return -1
+		return -1, nil
 	}
 	if a.PriceImpact.GreaterThan(b.PriceImpact.Fraction) {
-		return 1
+		return 1, nil
 	}
 	// finally consider the number of hops since each hop costs gas
-	return len(a.Route.Path) - len(b.Route.Path)
+	return len(a.Route.Path) - len(b.Route.Path), nil
 }
 
 // given an array of items sorted by `comparator`, insert an item into its sort index and constrain the size to
-// `maxSize` by removing the last item
-func SortedInsert(items []*Trade, add *Trade, maxSize int, comparator func(a, b *Trade) int) (sortedItems []*Trade, pop *Trade, err error) {
-	if maxSize <= 0 {
-		panic("MAX_SIZE_ZERO")
+// `maxSize` by removing the last item.
+// Returns ErrMaxSizeZero or ErrItemsSize for invalid sizes, and the error of the comparator if it fails.
+func SortedInsert(items []*Trade, add *Trade, maxSize int, comparator func(a, b *Trade) (int, error)) (sortedItems []*Trade, pop *Trade, err error) {
+	sortedItems, pop, _, err = sortedInsert(items, add, maxSize, comparator)
+	return sortedItems, pop, err
+}
+
+// candidate is a route found by the best trade search, its Trade is only built if it ranks among the best
+type candidate struct {
+	pairs       []*Pair
+	currencyIn  entities.Currency
+	currencyOut entities.Currency
+	tradeType   TradeType
+	amountIn    uint256.Int
+	amountOut   uint256.Int
+	trade       *Trade
+}
+
+// seed makes a candidate of a trade passed in as one of the best trades, ok is false if its amounts exceed 256 bits
+func seed(trade *Trade) (c *candidate, ok bool) {
+	c = &candidate{currencyIn: trade.InputAmount().Currency, currencyOut: trade.OutputAmount().Currency, trade: trade}
+	if c.amountIn.SetFromBig(trade.InputAmount().Quotient()) || c.amountOut.SetFromBig(trade.OutputAmount().Quotient()) {
+		return nil, false
 	}
-	itemsLen := len(items)
-	// this is an invariant because the interface cannot return multiple removed items if items.length exceeds maxSize
-	if itemsLen > maxSize {
-		panic("ITEMS_SIZE")
+	return c, true
+}
+
+func (c *candidate) InputAmount() *entities.CurrencyAmount {
+	if c.trade != nil {
+		return c.trade.InputAmount()
 	}
+	return entities.FromRawAmount(c.currencyIn, c.amountIn.ToBig())
+}
 
-	// short circuit first item add
-	if itemsLen == 0 {
-		items = append(items, add)
-		return items, nil, nil
+func (c *candidate) OutputAmount() *entities.CurrencyAmount {
+	if c.trade != nil {
+		return c.trade.OutputAmount()
 	}
+	return entities.FromRawAmount(c.currencyOut, c.amountOut.ToBig())
+}
 
-	isFull := (itemsLen == maxSize)
-	// short circuit if full and the additional item does not come before the last item
-	if isFull && comparator(items[itemsLen-1], add) <= 0 {
-		return items, add, nil
+// Trade builds the trade of the candidate once
+func (c *candidate) Trade() (*Trade, error) {
+	if c.trade != nil {
+		return c.trade, nil
+	}
+	route, err := NewRoute(c.pairs, c.currencyIn, c.currencyOut)
+	if err != nil {
+		return nil, err
+	}
+	amount, tradeType := entities.FromRawAmount(c.currencyIn, c.amountIn.ToBig()), ExactInput
+	if c.tradeType == ExactOutput {
+		amount, tradeType = entities.FromRawAmount(c.currencyOut, c.amountOut.ToBig()), ExactOutput
+	}
+	c.trade, err = NewTrade(route, amount, tradeType)
+	return c.trade, err
+}
+
+// compareCandidates ranks candidates as TradeComparator ranks their trades, building the trades only for ties
+func compareCandidates(a, b *candidate) (int, error) {
+	if a.currencyIn.Equal(b.currencyIn) && a.currencyOut.Equal(b.currencyOut) {
+		if comp := b.amountOut.Cmp(&a.amountOut); comp != 0 {
+			return comp, nil
+		}
+		if comp := a.amountIn.Cmp(&b.amountIn); comp != 0 {
+			return comp, nil
+		}
+	}
+	tradeA, err := a.Trade()
+	if err != nil {
+		return 0, err
+	}
+	tradeB, err := b.Trade()
+	if err != nil {
+		return 0, err
 	}
+	return TradeComparator(tradeA, tradeB)
+}
+
+// tradeSearch walks the routes through the pairs depth first, quoting the amounts on 256-bit integers
+type tradeSearch struct {
+	pairs         []*Pair
+	used          []bool
+	path          []*Pair // The pairs of the current route, in the order they were walked
+	currencyIn    entities.Currency
+	currencyOut   entities.Currency
+	amount        uint256.Int // The exact amount of the search
+	maxNumResults int
+	best          []*candidate
+}
 
-	lo, hi := 0, itemsLen
-	for lo < hi {
-		mid := (hi-lo)/2 + lo
-		if comparator(items[mid], add) <= 0 {
-			lo = mid + 1
-		} else {
-			hi = mid
+// newTradeSearch creates a search through the pairs that continues the route of currentPairs, ranking the
+// candidates with the best trades. amount is the exact amount of the trades.
+func newTradeSearch(pairs, currentPairs []*Pair, currencyIn, currencyOut entities.Currency, amount *entities.CurrencyAmount, maxNumResults int, bestTrades []*Trade) (*tradeSearch, error) {
+	s := &tradeSearch{
+		pairs:         append(append(make([]*Pair, 0, len(currentPairs)+len(pairs)), currentPairs...), pairs...),
+		used:          make([]bool, len(currentPairs)+len(pairs)),
+		path:          make([]*Pair, 0, len(currentPairs)+len(pairs)),
+		currencyIn:    currencyIn,
+		currencyOut:   currencyOut,
+		maxNumResults: maxNumResults,
+	}
+	for i := range currentPairs {
+		s.used[i] = true
+	}
+	if err := setAmount(&s.amount, amount); err != nil {
+		return nil, err
+	}
+	for _, trade := range bestTrades {
+		c, ok := seed(trade)
+		if !ok {
+			return nil, fmt.Errorf("%w: trade %s to %s", ErrAmountOverflow, trade.InputAmount().Quotient(), trade.OutputAmount().Quotient())
 		}
+		s.best = append(s.best, c)
 	}
+	return s, nil
+}
+
+// setAmount sets z to the amount, or returns ErrAmountOverflow if it does not fit an unsigned 256-bit integer
+func setAmount(z *uint256.Int, amount *entities.CurrencyAmount) error {
+	if amount.Quotient().Sign() < 0 || z.SetFromBig(amount.Quotient()) {
+		return fmt.Errorf("%w: %s", ErrAmountOverflow, amount.Quotient())
+	}
+	return nil
+}
+
+// add ranks the candidate of the current route
+func (s *tradeSearch) add(c *candidate) (err error) {
+	s.best, _, _, err = sortedInsert(s.best, c, s.maxNumResults, compareCandidates)
+	return err
+}
+
+// trades builds the trades of the best candidates
+func (s *tradeSearch) trades() ([]*Trade, error) {
+	var trades []*Trade
+	for _, c := range s.best {
+		trade, err := c.Trade()
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// exactIn searches the routes from tokenIn to the output currency, amountIn being the amount of tokenIn
+func (s *tradeSearch) exactIn(tokenIn, tokenOut *entities.Token, amountIn *uint256.Int, maxHops int) error {
+	remaining := len(s.pairs) - len(s.path)
+	for i, pair := range s.pairs {
+		if s.used[i] {
+			continue
+		}
+		// pair irrelevant
+		if !pair.InvolvesToken(tokenIn) || pair.empty() {
+			continue
+		}
+
+		var amountOut uint256.Int
+		if err := pair.QuoteOutput(&amountOut, tokenIn, amountIn); err != nil {
+			// input too low, or an output beyond 256 bits that no CurrencyAmount can hold
+			if errors.Is(err, ErrInsufficientInputAmount) || errors.Is(err, ErrAmountOverflow) {
+				continue
+			}
+			return err
+		}
+
+		// we have arrived at the output token, so this is the final trade of one of the paths
+		tokenNext := pair.Token0()
+		if tokenNext.Equal(tokenIn) {
+			tokenNext = pair.Token1()
+		}
+		if tokenNext.Equal(tokenOut) {
+			c := &candidate{
+				pairs:       append(append(make([]*Pair, 0, len(s.path)+1), s.path...), pair),
+				currencyIn:  s.currencyIn,
+				currencyOut: s.currencyOut,
+				tradeType:   ExactInput,
+				amountIn:    s.amount,
+				amountOut:   amountOut,
+			}
+			if err := s.add(c); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// otherwise, consider all the other paths that lead from this token as long as we have not exceeded maxHops
+		if maxHops > 1 && remaining > 1 {
+			s.used[i], s.path = true, append(s.path, pair)
+			err := s.exactIn(tokenNext, tokenOut, &amountOut, maxHops-1)
+			s.used[i], s.path = false, s.path[:len(s.path)-1]
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exactOut searches the routes from the input currency to tokenOut, amountOut being the amount of tokenOut.
+// The path is walked from the output, so it is in reverse order.
+func (s *tradeSearch) exactOut(tokenIn, tokenOut *entities.Token, amountOut *uint256.Int, maxHops int) error {
+	remaining := len(s.pairs) - len(s.path)
+	for i, pair := range s.pairs {
+		if s.used[i] {
+			continue
+		}
+		// pair irrelevant
+		if !pair.InvolvesToken(tokenOut) || pair.empty() {
+			continue
+		}
+
+		var amountIn uint256.Int
+		if err := pair.QuoteInput(&amountIn, tokenOut, amountOut); err != nil {
+			// not enough liquidity in this pair, or an input beyond 256 bits that no CurrencyAmount can hold
+			if errors.Is(err, ErrInsufficientReserves) || errors.Is(err, ErrAmountOverflow) {
+				continue
+			}
+			return err
+		}
+
+		// we have arrived at the input token, so this is the first trade of one of the paths
+		tokenPrev := pair.Token0()
+		if tokenPrev.Equal(tokenOut) {
+			tokenPrev = pair.Token1()
+		}
+		if tokenPrev.Equal(tokenIn) {
+			pairs := make([]*Pair, 0, len(s.path)+1)
+			pairs = append(pairs, pair)
+			for j := len(s.path) - 1; j >= 0; j-- {
+				pairs = append(pairs, s.path[j])
+			}
+			c := &candidate{
+				pairs:       pairs,
+				currencyIn:  s.currencyIn,
+				currencyOut: s.currencyOut,
+				tradeType:   ExactOutput,
+				amountIn:    amountIn,
+				amountOut:   s.amount,
+			}
+			if err := s.add(c); err != nil {
+				return err
+			}
+			continue
+		}
 
-	items = append(items[:lo], append([]*Trade{add}, items[lo:]...)...)
-	if isFull {
-		pop = items[itemsLen]
-		items = items[:itemsLen]
+		// otherwise, consider all the other paths that arrive at this token as long as we have not exceeded maxHops
+		if maxHops > 1 && remaining > 1 {
+			s.used[i], s.path = true, append(s.path, pair)
+			err := s.exactOut(tokenIn, tokenPrev, &amountIn, maxHops-1)
+			s.used[i], s.path = false, s.path[:len(s.path)-1]
+			if err != nil {
+				return err
+			}
+		}
 	}
-	return items, pop, nil
+	return nil
 }
 
 /**
@@ -130,6 +366,8 @@ func SortedInsert(items []*Trade, add *Trade, maxSize int, comparator func(a, b
  * amount to an output token, making at most `maxHops` hops.
  * Note this does not consider aggregation, as routes are linear. It's possible a better route exists by splitting
  * the amount in among multiple routes.
+ * Routes are quoted on 256-bit integers, and only the best ones are built into trades. Routes whose amounts exceed
+ * 256 bits are skipped, since no CurrencyAmount can hold them.
  * @param pairs the pairs to consider in finding the best trade
  * @param currencyAmountIn exact amount of input currency to spend
  * @param currencyOut the desired currency out
@@ -149,6 +387,9 @@ func BestTradeExactIn(
 	nextAmountIn *entities.CurrencyAmount,
 	bestTrades []*Trade,
 ) (sortedItems []*Trade, err error) {
+	if IsWrap(currencyAmountIn.Currency, currencyOut) {
+		return nil, ErrWrap
+	}
 	if nextAmountIn == nil {
 		nextAmountIn = currencyAmountIn
 	}
@@ -166,66 +407,27 @@ func BestTradeExactIn(
 		return nil, ErrInvalidRecursion
 	}
 
-	amountIn, tokenOut := nextAmountIn.Wrapped(), currencyOut.Wrapped()
-	for i := 0; i < len(pairs); i++ {
-		pair := pairs[i]
-		// pair irrelevant
-		if !pair.Token0().Equal(amountIn.Currency) && !pair.Token1().Equal(amountIn.Currency) {
-			continue
-		}
-		if pair.Reserve0().EqualTo(ZeroFraction) || pair.Reserve1().EqualTo(ZeroFraction) {
-			continue
-		}
-
-		amountOut, _, err := pair.GetOutputAmount(amountIn)
-		if err != nil {
-			// input too low
-			if err == ErrInsufficientInputAmount {
-				continue
-			}
-			return nil, err
-		}
-
-		// we have arrived at the output token, so this is the final trade of one of the paths
-		if amountOut.Currency.Equal(tokenOut) {
-			var route *Route
-			route, err = NewRoute(append(currentPairs, pair), currencyAmountIn.Currency, currencyOut)
-			if err != nil {
-				return nil, err
-			}
-			var trade *Trade
-			trade, err = NewTrade(route, currencyAmountIn, ExactInput)
-			if err != nil {
-				return nil, err
-			}
-			bestTrades, _, err = SortedInsert(bestTrades, trade, options.MaxNumResults, TradeComparator)
-			if err != nil {
-				return nil, err
-			}
-			continue
-		}
-
-		// otherwise, consider all the other paths that lead from this token as long as we have not exceeded maxHops
-		if options.MaxHops > 1 && len(pairs) > 1 {
-			pairsExcludingThisPair := make([]*Pair, len(pairs)-1)
-			copy(pairsExcludingThisPair, pairs[:i])
-			copy(pairsExcludingThisPair[i:], pairs[i+1:])
-			bestTrades, err = BestTradeExactIn(
-				pairsExcludingThisPair,
-				currencyAmountIn,
-				currencyOut,
-				options.ReduceHops(),
-				append(currentPairs, pair),
-				amountOut,
-				bestTrades,
-			)
-			if err != nil {
-				return nil, err
-			}
-		}
+	s, err := newTradeSearch(pairs, currentPairs, currencyAmountIn.Currency, currencyOut, currencyAmountIn, options.MaxNumResults, bestTrades)
+	if err != nil {
+		return nil, err
 	}
-
-	return bestTrades, nil
+	s.path = append(s.path, currentPairs...)
+	var amountIn uint256.Int
+	if err := setAmount(&amountIn, nextAmountIn); err != nil {
+		return nil, err
+	}
+	tokenIn, err := ResolveWrapped(nextAmountIn.Currency)
+	if err != nil {
+		return nil, err
+	}
+	tokenOut, err := ResolveWrapped(currencyOut)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exactIn(tokenIn, tokenOut, &amountIn, options.MaxHops); err != nil {
+		return nil, err
+	}
+	return s.trades()
 }
 
 /**
@@ -234,6 +436,7 @@ func BestTradeExactIn(
  * to an output token amount, making at most `maxHops` hops
  * note this does not consider aggregation, as routes are linear. it's possible a better route exists by splitting
  * the amount in among multiple routes.
+ * routes whose input exceeds 256 bits are skipped.
  * @param pairs the pairs to consider in finding the best trade
  * @param currencyIn the currency to spend
  * @param currencyAmountOut the exact amount of currency out
@@ -253,6 +456,9 @@ func BestTradeExactOut(
 	originalAmountOut *entities.CurrencyAmount,
 	bestTrades []*Trade,
 ) (sortedItems []*Trade, err error) {
+	if IsWrap(currencyIn, currencyAmountOut.Currency) {
+		return nil, ErrWrap
+	}
 	if originalAmountOut == nil {
 		originalAmountOut = currencyAmountOut
 	}
@@ -270,63 +476,28 @@ func BestTradeExactOut(
 		return nil, ErrInvalidRecursion
 	}
 
-	amountOut, tokenIn := currencyAmountOut.Wrapped(), currencyIn.Wrapped()
-	for i := 0; i < len(pairs); i++ {
-		pair := pairs[i]
-		// pair irrelevant
-		if !pair.Token0().Equal(amountOut.Currency) && !pair.Token1().Equal(amountOut.Currency) {
-			continue
-		}
-		if pair.Reserve0().EqualTo(ZeroFraction) || pair.Reserve1().EqualTo(ZeroFraction) {
-			continue
-		}
-
-		amountIn, _, err := pair.GetInputAmount(amountOut)
-		if err != nil {
-			// not enough liquidity in this pair
-			if err == ErrInsufficientReserves {
-				continue
-			}
-			return nil, err
-		}
-
-		// we have arrived at the input token, so this is the first trade of one of the paths
-		if amountIn.Currency.Equal(tokenIn) {
-			var route *Route
-			route, err = NewRoute(append([]*Pair{pair}, currentPairs...), currencyIn, originalAmountOut.Currency)
-			if err != nil {
-				return nil, err
-			}
-			var trade *Trade
-			trade, err = NewTrade(route, originalAmountOut, ExactOutput)
-			if err != nil {
-				return nil, err
-			}
-			bestTrades, _, err = SortedInsert(bestTrades, trade, options.MaxNumResults, TradeComparator)
-			if err != nil {
-				return nil, err
-			}
-			continue
-		}
-
-		// otherwise, consider all the other paths that arrive at this token as long as we have not exceeded maxHops
-		if options.MaxHops > 1 && len(pairs) > 1 {
-			pairsExcludingThisPair := make([]*Pair, len(pairs)-1)
-			copy(pairsExcludingThisPair, pairs[:i])
-			copy(pairsExcludingThisPair[i:], pairs[i+1:])
-			bestTrades, err = BestTradeExactOut(
-				pairsExcludingThisPair,
-				currencyIn,
-				amountIn,
-				options.ReduceHops(),
-				append([]*Pair{pair}, currentPairs...),
-				originalAmountOut,
-				bestTrades,
-			)
-			if err != nil {
-				return nil, err
-			}
-		}
+	s, err := newTradeSearch(pairs, currentPairs, currencyIn, originalAmountOut.Currency, originalAmountOut, options.MaxNumResults, bestTrades)
+	if err != nil {
+		return nil, err
+	}
+	// the path is kept from the output backwards
+	for i := len(currentPairs) - 1; i >= 0; i-- {
+		s.path = append(s.path, currentPairs[i])
+	}
+	var amountOut uint256.Int
+	if err := setAmount(&amountOut, currencyAmountOut); err != nil {
+		return nil, err
+	}
+	tokenIn, err := ResolveWrapped(currencyIn)
+	if err != nil {
+		return nil, err
+	}
+	tokenOut, err := ResolveWrapped(currencyAmountOut.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exactOut(tokenIn, tokenOut, &amountOut, options.MaxHops); err != nil {
+		return nil, err
 	}
-	return bestTrades, nil
+	return s.trades()
 }
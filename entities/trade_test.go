@@ -1,6 +1,7 @@
 package entities_test
 
 import (
+	"errors"
 	core "github.com/daoleno/uniswap-sdk-core/entities"
 	"github.com/vaulverin/uniswapv2-sdk/entities"
 	"math/big"
@@ -168,6 +169,17 @@ func TestTrade(t *testing.T) {
 			}
 		}
 
+		pairs = []*entities.Pair{pair_weth_0}
+		_, output = entities.BestTradeExactIn(pairs, core.FromRawAmount(ether, big.NewInt(100)), core.WETH9[1],
+			nil, nil, nil, nil)
+		// throws for a wrap of ether
+		{
+			expect := entities.ErrWrap
+			if expect != output {
+				t.Errorf("expect[%+v], but got[%+v]", expect, output)
+			}
+		}
+
 		pairs = []*entities.Pair{pair_0_1, pair_0_2, pair_1_2}
 		result, err := entities.BestTradeExactIn(pairs, tokenAmount_0_100, token2,
 			entities.NewDefaultBestTradeOptions(), nil, nil, nil)
@@ -669,6 +681,17 @@ func TestTrade(t *testing.T) {
 			}
 		}
 
+		pairs = []*entities.Pair{pair_weth_0}
+		_, output = entities.BestTradeExactOut(pairs, core.WETH9[1], core.FromRawAmount(ether, big.NewInt(100)),
+			nil, nil, nil, nil)
+		// throws for an unwrap of ether
+		{
+			expect := entities.ErrWrap
+			if expect != output {
+				t.Errorf("expect[%+v], but got[%+v]", expect, output)
+			}
+		}
+
 		pairs = []*entities.Pair{pair_0_1, pair_0_2, pair_1_2}
 		result, _ := entities.BestTradeExactOut(pairs, token0, tokenAmount_2_100,
 			nil, nil, nil, nil)
@@ -931,3 +954,38 @@ func TestTrade(t *testing.T) {
 		}
 	}
 }
+
+func TestComparatorErrors(t *testing.T) {
+	token0 := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000001"), 18, "t0", "")
+	token1 := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000002"), 18, "t1", "")
+	token2 := core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000003"), 18, "t2", "")
+	pair_0_1, _ := entities.NewPair(core.FromRawAmount(token0, big.NewInt(1000)), core.FromRawAmount(token1, big.NewInt(1000)), nil)
+	pair_0_2, _ := entities.NewPair(core.FromRawAmount(token0, big.NewInt(1000)), core.FromRawAmount(token2, big.NewInt(1000)), nil)
+	route_0_1, _ := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	route_0_2, _ := entities.NewRoute([]*entities.Pair{pair_0_2}, token0, token2)
+	trade_0_1, _ := entities.ExactIn(route_0_1, core.FromRawAmount(token0, big.NewInt(100)))
+	trade_0_2, _ := entities.ExactIn(route_0_2, core.FromRawAmount(token0, big.NewInt(100)))
+
+	// trades between different currencies cannot be compared
+	_, err := entities.TradeComparator(trade_0_1, trade_0_2)
+	var currencyErr *entities.CurrencyError
+	if !errors.As(err, &currencyErr) || !errors.Is(err, entities.ErrInvalidCurrency) {
+		t.Fatalf("expect[%+v], but got[%+v]", entities.ErrInvalidCurrency, err)
+	}
+	if !currencyErr.Expected.Equal(token1) || !currencyErr.Actual.Equal(token2) {
+		t.Errorf("expect[%+v %+v], but got[%+v %+v]", token1, token2, currencyErr.Expected, currencyErr.Actual)
+	}
+	_, _, err = entities.SortedInsert([]*entities.Trade{trade_0_1}, trade_0_2, 2, entities.TradeComparator)
+	if !errors.Is(err, entities.ErrInvalidCurrency) {
+		t.Errorf("expect[%+v], but got[%+v]", entities.ErrInvalidCurrency, err)
+	}
+
+	_, _, err = entities.SortedInsert(nil, trade_0_1, 0, entities.TradeComparator)
+	if !errors.Is(err, entities.ErrMaxSizeZero) {
+		t.Errorf("expect[%+v], but got[%+v]", entities.ErrMaxSizeZero, err)
+	}
+	_, _, err = entities.SortedInsert([]*entities.Trade{trade_0_1, trade_0_1}, trade_0_1, 1, entities.TradeComparator)
+	if !errors.Is(err, entities.ErrItemsSize) {
+		t.Errorf("expect[%+v], but got[%+v]", entities.ErrItemsSize, err)
+	}
+}
@@ -0,0 +1,215 @@
+package entities
+
+import (
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+var (
+	b1994    = big.NewInt(1994)
+	b1997    = big.NewInt(1997)
+	b3988000 = big.NewInt(3988000)
+	b3988009 = big.NewInt(3988009)
+)
+
+// ZapInAmounts describes a single sided deposit: a part of the input is swapped for the other token
+// and both are added as liquidity to the same pair.
+type ZapInAmounts struct {
+	Pair       *Pair                // Pair before the swap
+	NextPair   *Pair                // Pair after the swap, the liquidity is added to it
+	SwapAmount *core.CurrencyAmount // Amount of the input currency to swap
+	SwapOutput *core.CurrencyAmount // Amount of the other token received by the swap
+	AmountA    *core.CurrencyAmount // Amount of the input currency left to add as liquidity
+	AmountB    *core.CurrencyAmount // Amount of the other token to add as liquidity
+	Liquidity  *core.CurrencyAmount // Liquidity tokens minted for AmountA and AmountB, an upper bound of what the router calls mint
+}
+
+// GetZapInAmounts returns the amount of the input currency to swap so that the remainder and the swap output
+// match the reserve ratio after the swap, accounting for the 0.3% fee, i.e.
+// (sqrt(reserveIn * (3988009 * reserveIn + 3988000 * amountIn)) - 1997 * reserveIn) / 1994
+// @param totalSupply total supply of the liquidity token
+// @param amountIn amount of a pair token, or of the native currency of a WETH pair, to deposit
+// @param feeOn whether the protocol fee is on
+// @param kLast the value of the kLast of the pair, required if feeOn is true
+func (p *Pair) GetZapInAmounts(totalSupply, amountIn *core.CurrencyAmount, feeOn bool, kLast *big.Int) (*ZapInAmounts, error) {
+	tokenIn := WrappedOf(amountIn.Currency)
+	if !p.InvolvesToken(tokenIn) {
+		return nil, p.pairError(ErrDiffToken, nil, amountIn)
+	}
+	reserveIn, err := p.ReserveOf(tokenIn)
+	if err != nil {
+		return nil, err
+	}
+
+	swapAmount := big.NewInt(0).Mul(reserveIn.Quotient(), b3988009)
+	swapAmount.Add(swapAmount, big.NewInt(0).Mul(amountIn.Quotient(), b3988000))
+	swapAmount.Mul(swapAmount, reserveIn.Quotient())
+	swapAmount.Sqrt(swapAmount)
+	swapAmount.Sub(swapAmount, big.NewInt(0).Mul(reserveIn.Quotient(), b1997))
+	swapAmount.Div(swapAmount, b1994)
+
+	swapOutput, nextPair, err := p.GetOutputAmount(core.FromRawAmount(tokenIn, swapAmount))
+	if err != nil {
+		return nil, err
+	}
+
+	amountA := core.FromRawAmount(amountIn.Currency, big.NewInt(0).Sub(amountIn.Quotient(), swapAmount))
+	liquidity, err := nextPair.GetLiquidityMinted(totalSupply, WrappedAmount(amountA), swapOutput, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZapInAmounts{
+		Pair:       p,
+		NextPair:   nextPair,
+		SwapAmount: core.FromRawAmount(amountIn.Currency, swapAmount),
+		SwapOutput: swapOutput,
+		AmountA:    amountA,
+		AmountB:    swapOutput,
+		Liquidity:  liquidity,
+	}, nil
+}
+
+// Quote returns the amount of the other token with the same value as amount at the current reserves,
+// as UniswapV2Library.quote does when adding liquidity
+func (p *Pair) Quote(amount *core.CurrencyAmount) (*core.CurrencyAmount, error) {
+	reserveA, err := p.ReserveOf(WrappedOf(amount.Currency))
+	if err != nil {
+		return nil, err
+	}
+	token := p.Token0()
+	if WrappedOf(amount.Currency).Equal(p.Token0()) {
+		token = p.Token1()
+	}
+	reserveB, err := p.ReserveOf(token)
+	if err != nil {
+		return nil, err
+	}
+	if reserveA.Quotient().Cmp(Zero) == 0 || reserveB.Quotient().Cmp(Zero) == 0 {
+		return nil, p.pairError(ErrInsufficientReserves, nil, amount)
+	}
+
+	quoted := big.NewInt(0).Mul(amount.Quotient(), reserveB.Quotient())
+	quoted.Div(quoted, reserveA.Quotient())
+	return core.FromRawAmount(token, quoted), nil
+}
+
+// ZapOutAmounts describes a withdrawal into a single currency: the liquidity is removed and the other token
+// is swapped into the output currency against the pair after the burn.
+type ZapOutAmounts struct {
+	Pair         *Pair                // Pair before the burn
+	BurnedPair   *Pair                // Pair after the burn, the swap goes through it
+	NextPair     *Pair                // Pair after the burn and the swap
+	Liquidity    *core.CurrencyAmount // Liquidity tokens to burn
+	AmountA      *core.CurrencyAmount // Amount of the output currency received by the burn
+	AmountB      *core.CurrencyAmount // Amount of the other token received by the burn and swapped
+	SwapOutput   *core.CurrencyAmount // Amount of the output currency received by the swap
+	OutputAmount *core.CurrencyAmount // Total amount of the output currency
+}
+
+// GetZapOutAmounts returns the amounts of a withdrawal of liquidity into the output currency.
+// @param currencyOut a pair token, or the native currency of a WETH pair
+// @param totalSupply total supply of the liquidity token
+// @param liquidity liquidity tokens to burn
+// @param feeOn whether the protocol fee is on
+// @param kLast the value of the kLast of the pair, required if feeOn is true
+func (p *Pair) GetZapOutAmounts(currencyOut core.Currency, totalSupply, liquidity *core.CurrencyAmount, feeOn bool, kLast *big.Int) (*ZapOutAmounts, error) {
+	tokenOut := WrappedOf(currencyOut)
+	if !p.InvolvesToken(tokenOut) {
+		return nil, p.pairError(ErrDiffToken, tokenOut, nil)
+	}
+	tokenB := p.Token0()
+	if tokenOut.Equal(p.Token0()) {
+		tokenB = p.Token1()
+	}
+
+	amountA, err := p.GetLiquidityValue(tokenOut, totalSupply, liquidity, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+	amountB, err := p.GetLiquidityValue(tokenB, totalSupply, liquidity, feeOn, kLast)
+	if err != nil {
+		return nil, err
+	}
+
+	reserveA, err := p.ReserveOf(tokenOut)
+	if err != nil {
+		return nil, err
+	}
+	reserveB, err := p.ReserveOf(tokenB)
+	if err != nil {
+		return nil, err
+	}
+	burnedPair, err := NewPair(reserveA.Subtract(amountA), reserveB.Subtract(amountB), p.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	swapOutput, nextPair, err := burnedPair.GetOutputAmount(amountB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZapOutAmounts{
+		Pair:         p,
+		BurnedPair:   burnedPair,
+		NextPair:     nextPair,
+		Liquidity:    liquidity,
+		AmountA:      core.FromRawAmount(currencyOut, amountA.Quotient()),
+		AmountB:      amountB,
+		SwapOutput:   core.FromRawAmount(currencyOut, swapOutput.Quotient()),
+		OutputAmount: core.FromRawAmount(currencyOut, big.NewInt(0).Add(amountA.Quotient(), swapOutput.Quotient())),
+	}, nil
+}
+
+// ZapOutMinimums are the least amounts the calls of a withdrawal into a single currency accept
+type ZapOutMinimums struct {
+	AmountA      *core.CurrencyAmount // Least amount of the output currency received by the burn
+	AmountB      *core.CurrencyAmount // Least amount of the other token received by the burn, it is the amount swapped
+	SwapOutput   *core.CurrencyAmount // Least amount of the output currency received by the swap
+	OutputAmount *core.CurrencyAmount // Least total amount of the output currency, AmountA plus SwapOutput
+}
+
+// Minimums returns the least amounts of the withdrawal for the given slippage tolerance. The tolerance applies
+// once: the burned amounts and the swap output are their quoted amounts reduced by it. Only AmountB is swapped,
+// so SwapOutput is also capped by its output if the pair is as expected after the burn, which may round lower.
+// @param slippageTolerance tolerance of unfavorable slippage from the quoted amounts
+func (z *ZapOutAmounts) Minimums(slippageTolerance *core.Percent) (*ZapOutMinimums, error) {
+	if slippageTolerance.LessThan(ZeroFraction) {
+		return nil, ErrInvalidSlippageTolerance
+	}
+	reduce := func(amount *core.CurrencyAmount) *core.CurrencyAmount {
+		reduced := core.NewFraction(One, One).
+			Add(slippageTolerance.Fraction).
+			Invert().
+			Multiply(core.NewFraction(amount.Quotient(), One)).Quotient()
+		return core.FromRawAmount(amount.Currency, reduced)
+	}
+
+	amountA, amountB, swapOutput := reduce(z.AmountA), reduce(z.AmountB), reduce(z.SwapOutput)
+	swapped, _, err := z.BurnedPair.GetOutputAmount(amountB)
+	if err != nil {
+		return nil, err
+	}
+	if swapped.Quotient().Cmp(swapOutput.Quotient()) < 0 {
+		swapOutput = core.FromRawAmount(swapOutput.Currency, swapped.Quotient())
+	}
+	return &ZapOutMinimums{
+		AmountA:      amountA,
+		AmountB:      amountB,
+		SwapOutput:   swapOutput,
+		OutputAmount: amountA.Add(swapOutput),
+	}, nil
+}
+
+// MinimumAmountOut returns the minimum total output for the given slippage tolerance, the one the calls of
+// router.ZapOutCallParameters enforce
+// @param slippageTolerance tolerance of unfavorable slippage from the quoted output
+func (z *ZapOutAmounts) MinimumAmountOut(slippageTolerance *core.Percent) (*core.CurrencyAmount, error) {
+	minimums, err := z.Minimums(slippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+	return minimums.OutputAmount, nil
+}
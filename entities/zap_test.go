@@ -0,0 +1,120 @@
+package entities_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+func TestGetZapInAmounts(t *testing.T) {
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(DAI, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	amountIn := core.FromRawAmount(DAI, big.NewInt(10000))
+
+	zap, err := pair.GetZapInAmounts(totalSupply, amountIn, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// swaps about a half of the input
+	if zap.SwapAmount.Quotient().Cmp(big.NewInt(4995)) != 0 {
+		t.Errorf("expect[4995], but got[%s]", zap.SwapAmount.Quotient())
+	}
+	if zap.AmountA.Quotient().Cmp(big.NewInt(5005)) != 0 {
+		t.Errorf("expect[5005], but got[%s]", zap.AmountA.Quotient())
+	}
+	if !zap.AmountB.Currency.Equal(USDC) || zap.AmountB.Quotient().Cmp(big.NewInt(4955)) != 0 {
+		t.Errorf("expect[4955 USDC], but got[%s %s]", zap.AmountB.Quotient(), zap.AmountB.Currency.Symbol())
+	}
+
+	// the remainder matches the reserve ratio after the swap
+	quoted, err := zap.NextPair.Quote(zap.AmountA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := big.NewInt(0).Sub(quoted.Quotient(), zap.AmountB.Quotient())
+	if diff.CmpAbs(big.NewInt(1)) > 0 {
+		t.Errorf("remainder does not match the reserves: %s vs %s", quoted.Quotient(), zap.AmountB.Quotient())
+	}
+
+	// no other swap amount mints more liquidity
+	for _, delta := range []int64{-20, -1, 1, 20} {
+		swapAmount := big.NewInt(0).Add(zap.SwapAmount.Quotient(), big.NewInt(delta))
+		output, nextPair, err := pair.GetOutputAmount(core.FromRawAmount(DAI, swapAmount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		remainder := core.FromRawAmount(DAI, big.NewInt(0).Sub(amountIn.Quotient(), swapAmount))
+		liquidity, err := nextPair.GetLiquidityMinted(totalSupply, remainder, output, false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if liquidity.GreaterThan(zap.Liquidity.Fraction) {
+			t.Errorf("swap of %s mints %s > %s", swapAmount, liquidity.Quotient(), zap.Liquidity.Quotient())
+		}
+	}
+
+	// cannot deposit a token that is not in the pair
+	{
+		_, err := pair.GetZapInAmounts(totalSupply, core.FromRawAmount(core.WETH9[1], B100), false, nil)
+		if !errors.Is(err, entities.ErrDiffToken) {
+			t.Errorf("expect[%+v], but got[%+v]", entities.ErrDiffToken, err)
+		}
+	}
+}
+
+func TestGetZapOutAmounts(t *testing.T) {
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(USDC, million), core.FromRawAmount(DAI, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	liquidity := core.FromRawAmount(pair.LiquidityToken, big.NewInt(10000))
+
+	zap, err := pair.GetZapOutAmounts(DAI, totalSupply, liquidity, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the swap goes through the pair reduced by the burned amounts
+	if zap.BurnedPair.Reserve0().Quotient().Cmp(big.NewInt(990000)) != 0 ||
+		zap.BurnedPair.Reserve1().Quotient().Cmp(big.NewInt(990000)) != 0 {
+		t.Errorf("wrong reserves after burn %s %s", zap.BurnedPair.Reserve0().Quotient(), zap.BurnedPair.Reserve1().Quotient())
+	}
+	if zap.AmountA.Quotient().Cmp(big.NewInt(10000)) != 0 || zap.AmountB.Quotient().Cmp(big.NewInt(10000)) != 0 {
+		t.Errorf("expect[10000 10000], but got[%s %s]", zap.AmountA.Quotient(), zap.AmountB.Quotient())
+	}
+	if zap.SwapOutput.Quotient().Cmp(big.NewInt(9870)) != 0 {
+		t.Errorf("expect[9870], but got[%s]", zap.SwapOutput.Quotient())
+	}
+	if !zap.OutputAmount.Currency.Equal(DAI) || zap.OutputAmount.Quotient().Cmp(big.NewInt(19870)) != 0 {
+		t.Errorf("expect[19870 DAI], but got[%s %s]", zap.OutputAmount.Quotient(), zap.OutputAmount.Currency.Symbol())
+	}
+	minimum, err := zap.MinimumAmountOut(core.NewPercent(big.NewInt(1), B100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 9900 from the burn and 9772 from the swap, the slippage applies once to each
+	if minimum.Quotient().Cmp(big.NewInt(19672)) != 0 {
+		t.Errorf("expect[19672], but got[%s]", minimum.Quotient())
+	}
+
+	// the protocol fee dilutes the burned liquidity
+	{
+		kLast := big.NewInt(0).Mul(big.NewInt(900000), big.NewInt(900000))
+		zapFeeOn, err := pair.GetZapOutAmounts(DAI, totalSupply, liquidity, true, kLast)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !zapFeeOn.OutputAmount.LessThan(zap.OutputAmount.Fraction) {
+			t.Errorf("expect less than %s, but got[%s]", zap.OutputAmount.Quotient(), zapFeeOn.OutputAmount.Quotient())
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package fake is an in-memory Uniswap V2 exchange for testing code that builds router calls without an EVM.
+// It executes the swaps of router.SwapCallParameters against its pairs and balances, reverting like Router02.
+package fake
+
+import (
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+// Router02 revert reasons
+var (
+	ErrExpired                  = router.ErrExpired
+	ErrInsufficientOutputAmount = router.ErrInsufficientOutputAmount
+	ErrExcessiveInputAmount     = router.ErrExcessiveInputAmount
+	ErrInvalidPath              = router.ErrInvalidPath
+	ErrInsufficientLiquidity    = router.ErrInsufficientLiquidity
+	ErrInsufficientInputAmount  = router.ErrInsufficientInputAmount
+	ErrZeroOutputAmount         = router.ErrZeroOutputAmount
+	ErrPairOutputAmount         = router.ErrPairOutputAmount
+	ErrOverflow                 = router.ErrOverflow
+	ErrTransferFailed           = errors.New("TransferHelper: TRANSFER_FROM_FAILED")
+	ErrETHTransferFailed        = errors.New("TransferHelper: ETH_TRANSFER_FAILED")
+)
+
+var (
+	ErrUnknownMethod = router.ErrUnknownMethod
+	ErrInvalidArgs   = router.ErrInvalidArgs
+	ErrNoPair        = router.ErrUnknownPair
+)
+
+// Exchange holds pairs, token balances and ether balances.
+// Tokens have no transfer fee and transfers need no allowance.
+type Exchange struct {
+	WETH  *core.Token  // The wrapped native token of the ETH methods
+	Clock router.Clock // Tells the block time deadlines are checked against. Defaults to router.SystemClock.
+
+	pairs    map[router.PairKey]*entities.Pair
+	tokens   map[common.Address]*core.Token
+	balances map[common.Address]map[common.Address]*big.Int // token => account => balance
+	ether    map[common.Address]*big.Int
+}
+
+// NewExchange creates an exchange with the pairs
+func NewExchange(weth *core.Token, clock router.Clock, pairs ...*entities.Pair) *Exchange {
+	e := &Exchange{
+		WETH:     weth,
+		Clock:    clock,
+		pairs:    map[router.PairKey]*entities.Pair{},
+		tokens:   map[common.Address]*core.Token{weth.Address: weth},
+		balances: map[common.Address]map[common.Address]*big.Int{},
+		ether:    map[common.Address]*big.Int{},
+	}
+	for _, pair := range pairs {
+		e.SetPair(pair)
+	}
+	return e
+}
+
+// SetPair adds the pair or replaces the pair of the same tokens
+func (e *Exchange) SetPair(pair *entities.Pair) {
+	e.pairs[router.PairKeyOf(pair.Token0().Address, pair.Token1().Address)] = pair
+	e.tokens[pair.Token0().Address] = pair.Token0()
+	e.tokens[pair.Token1().Address] = pair.Token1()
+}
+
+// Pair returns the current pair of the tokens, or nil
+func (e *Exchange) Pair(tokenA, tokenB common.Address) *entities.Pair {
+	return e.pairs[router.PairKeyOf(tokenA, tokenB)]
+}
+
+// Pairs returns the current pairs
+func (e *Exchange) Pairs() []*entities.Pair {
+	pairs := make([]*entities.Pair, 0, len(e.pairs))
+	for _, pair := range e.pairs {
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// BalanceOf returns the balance of the account, in wei for the native currency
+func (e *Exchange) BalanceOf(currency core.Currency, account common.Address) *big.Int {
+	var balance *big.Int
+	if currency.IsNative() {
+		balance = e.ether[account]
+	} else {
+		balance = e.balances[entities.WrappedOf(currency).Address][account]
+	}
+	if balance == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(balance)
+}
+
+// Mint credits the account with the amount, in wei for the native currency
+func (e *Exchange) Mint(account common.Address, amount *core.CurrencyAmount) {
+	e.credit(amount.Currency, account, amount.Quotient())
+}
+
+func (e *Exchange) credit(currency core.Currency, account common.Address, amount *big.Int) {
+	if currency.IsNative() {
+		e.ether[account] = new(big.Int).Add(e.BalanceOf(currency, account), amount)
+		return
+	}
+	token := entities.WrappedOf(currency).Address
+	if e.balances[token] == nil {
+		e.balances[token] = map[common.Address]*big.Int{}
+	}
+	e.balances[token][account] = new(big.Int).Add(e.BalanceOf(currency, account), amount)
+}
+
+// debit fails with err if the balance is insufficient
+func (e *Exchange) debit(currency core.Currency, account common.Address, amount *big.Int, err error) error {
+	balance := e.BalanceOf(currency, account)
+	if balance.Cmp(amount) < 0 {
+		return err
+	}
+	e.credit(currency, account, new(big.Int).Neg(amount))
+	return nil
+}
+
+// Execute runs the router call sent by the account and returns the amounts of each step of the path.
+// A reverted call changes nothing.
+func (e *Exchange) Execute(from common.Address, params *router.MethodParameters) ([]*big.Int, error) {
+	clock := e.Clock
+	if clock == nil {
+		clock = router.SystemClock
+	}
+	s, err := router.ApplySwap(params, e.Pairs(), e.WETH.Address, uint64(clock.Now().Unix()))
+	if err != nil {
+		return nil, err
+	}
+	if s.Reverted() {
+		return nil, s.Revert
+	}
+
+	var currencyIn, currencyOut core.Currency = e.tokens[s.Path[0]], e.tokens[s.Path[len(s.Path)-1]]
+	if s.EtherIn {
+		currencyIn = core.EtherOnChain(e.WETH.ChainId())
+	}
+	if s.EtherOut {
+		currencyOut = core.EtherOnChain(e.WETH.ChainId())
+	}
+	amountIn, transferFailed := s.AmountIn(), ErrTransferFailed
+	if s.EtherIn {
+		// the value is sent with the call, the router refunds what it does not spend
+		amountIn, transferFailed = s.Value, ErrETHTransferFailed
+	}
+	if err := e.debit(currencyIn, from, amountIn, transferFailed); err != nil {
+		return nil, err
+	}
+	if s.EtherIn {
+		e.credit(currencyIn, from, new(big.Int).Sub(s.Value, s.AmountIn()))
+	}
+	for _, pair := range s.Pairs {
+		e.SetPair(pair)
+	}
+	e.credit(currencyOut, s.To, s.AmountOut())
+	return s.Amounts, nil
+}
@@ -0,0 +1,161 @@
+package fake_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/fake"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+var (
+	trader    = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	recipient = common.HexToAddress("0x0000000000000000000000000000000000000004")
+	ether     = core.EtherOnChain(1)
+	weth      = core.WETH9[1]
+	token0    = core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000001"), 18, "t0", "t0")
+	token1    = core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000002"), 18, "t1", "t1")
+	now       = time.Unix(1700000000, 0)
+)
+
+func newExchange(t *testing.T) *fake.Exchange {
+	pair_0_1, err := entities.NewPair(core.FromRawAmount(token0, big.NewInt(1000)), core.FromRawAmount(token1, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair_weth_0, err := entities.NewPair(core.FromRawAmount(weth, big.NewInt(1000)), core.FromRawAmount(token0, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := fake.NewExchange(weth, router.FixedClock(now), pair_0_1, pair_weth_0)
+	e.Mint(trader, core.FromRawAmount(token0, big.NewInt(500)))
+	e.Mint(trader, core.FromRawAmount(token1, big.NewInt(500)))
+	e.Mint(trader, core.FromRawAmount(ether, big.NewInt(500)))
+	return e
+}
+
+func trade(t *testing.T, e *fake.Exchange, in, out core.Currency, amount *core.CurrencyAmount, tradeType entities.TradeType) *entities.Trade {
+	trades, err := entities.BestTradeExactIn(e.Pairs(), amount, out, nil, nil, nil, nil)
+	if tradeType == entities.ExactOutput {
+		trades, err = entities.BestTradeExactOut(e.Pairs(), in, amount, nil, nil, nil, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trades[0]
+}
+
+func options() router.TradeOptions {
+	return router.TradeOptions{
+		AllowedSlippage: core.NewPercent(big.NewInt(1), big.NewInt(100)),
+		Recipient:       recipient,
+		Clock:           router.FixedClock(now),
+	}
+}
+
+func check(t *testing.T, expect, output interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expect, output) {
+		t.Errorf("expect[%+v], but got[%+v]", expect, output)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		Name   string
+		In     core.Currency
+		Out    core.Currency
+		Amount *core.CurrencyAmount
+		Type   entities.TradeType
+	}{
+		{"exact tokens for tokens", token0, token1, core.FromRawAmount(token0, big.NewInt(100)), entities.ExactInput},
+		{"tokens for exact tokens", token0, token1, core.FromRawAmount(token1, big.NewInt(100)), entities.ExactOutput},
+		{"exact ETH for tokens", ether, token1, core.FromRawAmount(ether, big.NewInt(100)), entities.ExactInput},
+		{"ETH for exact tokens", ether, token1, core.FromRawAmount(token1, big.NewInt(100)), entities.ExactOutput},
+		{"exact tokens for ETH", token1, ether, core.FromRawAmount(token1, big.NewInt(100)), entities.ExactInput},
+		{"tokens for exact ETH", token1, ether, core.FromRawAmount(ether, big.NewInt(100)), entities.ExactOutput},
+	}
+	for _, test := range tests {
+		e := newExchange(t)
+		tr := trade(t, e, test.In, test.Out, test.Amount, test.Type)
+		params, err := router.SwapCallParameters(tr, options())
+		if err != nil {
+			t.Fatal(err)
+		}
+		balanceIn := e.BalanceOf(test.In, trader)
+		amounts, err := e.Execute(trader, params)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		check(t, tr.InputAmount().Quotient(), amounts[0])
+		check(t, tr.OutputAmount().Quotient(), amounts[len(amounts)-1])
+		check(t, tr.OutputAmount().Quotient(), e.BalanceOf(test.Out, recipient))
+		check(t, new(big.Int).Sub(balanceIn, tr.InputAmount().Quotient()), e.BalanceOf(test.In, trader))
+		// the pairs moved to the reserves after the trade
+		for _, pair := range tr.Route.Pairs {
+			next := e.Pair(pair.Token0().Address, pair.Token1().Address)
+			check(t, false, next.Reserve0().EqualTo(pair.Reserve0().Fraction))
+		}
+	}
+}
+
+func TestExecuteReverts(t *testing.T) {
+	e := newExchange(t)
+	tr := trade(t, e, token0, token1, core.FromRawAmount(token0, big.NewInt(100)), entities.ExactInput)
+	params, _ := router.SwapCallParameters(tr, options())
+
+	// the price moved against the trade
+	front := trade(t, e, token0, token1, core.FromRawAmount(token0, big.NewInt(50)), entities.ExactInput)
+	frontParams, _ := router.SwapCallParameters(front, options())
+	if _, err := e.Execute(trader, frontParams); err != nil {
+		t.Fatal(err)
+	}
+	balance := e.BalanceOf(token0, trader)
+	_, err := e.Execute(trader, params)
+	check(t, fake.ErrInsufficientOutputAmount, err)
+	check(t, balance, e.BalanceOf(token0, trader))
+
+	tr = trade(t, e, token0, token1, core.FromRawAmount(token1, big.NewInt(100)), entities.ExactOutput)
+	params, _ = router.SwapCallParameters(tr, options())
+	front = trade(t, e, token0, token1, core.FromRawAmount(token0, big.NewInt(50)), entities.ExactInput)
+	frontParams, _ = router.SwapCallParameters(front, options())
+	if _, err := e.Execute(trader, frontParams); err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Execute(trader, params)
+	check(t, fake.ErrExcessiveInputAmount, err)
+
+	params.Args[len(params.Args)-1] = big.NewInt(now.Unix() - 1)
+	_, err = e.Execute(trader, params)
+	check(t, fake.ErrExpired, err)
+
+	invalid := &router.MethodParameters{
+		MethodName: "swapExactETHForTokens",
+		Args:       []interface{}{big.NewInt(0), []common.Address{token0.Address, token1.Address}, recipient, big.NewInt(now.Unix())},
+		Value:      big.NewInt(10),
+	}
+	_, err = e.Execute(trader, invalid)
+	check(t, fake.ErrInvalidPath, err)
+
+	invalid = &router.MethodParameters{
+		MethodName: "swapExactTokensForTokens",
+		Args:       []interface{}{big.NewInt(10), big.NewInt(0), []common.Address{token0.Address}, recipient, big.NewInt(now.Unix())},
+	}
+	_, err = e.Execute(trader, invalid)
+	check(t, fake.ErrInvalidPath, err)
+
+	invalid.Args[0] = big.NewInt(10000)
+	invalid.Args[2] = []common.Address{token0.Address, token1.Address}
+	_, err = e.Execute(trader, invalid)
+	check(t, fake.ErrTransferFailed, err)
+
+	invalid.MethodName = "addLiquidity"
+	_, err = e.Execute(trader, invalid)
+	check(t, true, errors.Is(err, fake.ErrUnknownMethod))
+}
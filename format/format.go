@@ -0,0 +1,207 @@
+// Package format converts between human-readable decimal strings and the raw amounts of the sdk, e.g. "1.5 WETH"
+// and 1500000000000000000 wei. Formatting is exact rational arithmetic with round half up, so the same value always
+// displays the same on every caller, be it a CLI, an HTTP service or log lines.
+package format
+
+import (
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+// Exact as Options.Decimals shows every decimal of the value, without trailing zeros. Values that do not
+// terminate, e.g. 1/3, are rounded to MaxDecimals.
+const Exact = -1
+
+// MaxDecimals is the most decimals shown by Exact
+const MaxDecimals = 36
+
+// Options of formatting
+type Options struct {
+	// Round to this many significant digits and drop the trailing zeros of the decimals. Takes precedence over
+	// Decimals if positive.
+	SignificantDigits int
+	// Round to this many decimals and keep the trailing zeros, or Exact
+	Decimals int
+	// Separates the thousands of the integer part, e.g. ","
+	ThousandsSeparator string
+	// Append the symbol of the currency, or quote/base for prices
+	Symbol bool
+}
+
+var (
+	DefaultAmountOptions  = &Options{Decimals: Exact}
+	DefaultPriceOptions   = &Options{SignificantDigits: 6}
+	DefaultPercentOptions = &Options{Decimals: 2}
+)
+
+var ten = big.NewInt(10)
+
+// Amount formats the amount in units of its currency, nil options are DefaultAmountOptions
+func Amount(amount *core.CurrencyAmount, opts *Options) string {
+	if opts == nil {
+		opts = DefaultAmountOptions
+	}
+	text := Fraction(amount.Numerator, new(big.Int).Mul(amount.Denominator, amount.DecimalScale), opts)
+	if opts.Symbol {
+		text += " " + amount.Currency.Symbol()
+	}
+	return text
+}
+
+// Price formats the price of one base unit in quote units, nil options are DefaultPriceOptions
+func Price(price *core.Price, opts *Options) string {
+	if opts == nil {
+		opts = DefaultPriceOptions
+	}
+	adjusted := price.Fraction.Multiply(price.Scalar)
+	text := Fraction(adjusted.Numerator, adjusted.Denominator, opts)
+	if opts.Symbol {
+		text += " " + price.QuoteCurrency.Symbol() + "/" + price.BaseCurrency.Symbol()
+	}
+	return text
+}
+
+// Percent formats the percent, e.g. a price impact, with a % sign; nil options are DefaultPercentOptions.
+// Symbol does not apply.
+func Percent(percent *core.Percent, opts *Options) string {
+	if opts == nil {
+		opts = DefaultPercentOptions
+	}
+	return Fraction(new(big.Int).Mul(percent.Numerator, big.NewInt(100)), percent.Denominator, opts) + "%"
+}
+
+// Fraction formats numerator/denominator. Symbol does not apply.
+func Fraction(numerator, denominator *big.Int, opts *Options) string {
+	if denominator.Sign() == 0 {
+		return "NaN"
+	}
+	if opts == nil {
+		opts = DefaultAmountOptions
+	}
+	value := new(big.Rat).SetFrac(numerator, denominator)
+	negative := value.Sign() < 0
+	value.Abs(value)
+
+	var digits *big.Int
+	var decimals int
+	trim := true
+	switch {
+	case value.Sign() == 0:
+		digits = new(big.Int)
+		if opts.SignificantDigits <= 0 && opts.Decimals > 0 {
+			decimals, trim = opts.Decimals, false
+		}
+	case opts.SignificantDigits > 0:
+		decimals = opts.SignificantDigits - 1 - magnitude(value)
+		digits = round(value, decimals)
+		// rounding up may add a digit, e.g. 9.996 to 10.00
+		if len(digits.String()) > opts.SignificantDigits {
+			decimals--
+			digits = round(value, decimals)
+		}
+	case opts.Decimals == Exact:
+		decimals = exactDecimals(value)
+		digits = round(value, decimals)
+	default:
+		decimals, trim = opts.Decimals, false
+		digits = round(value, decimals)
+	}
+
+	text := render(digits, decimals, trim, opts.ThousandsSeparator)
+	if negative && digits.Sign() != 0 {
+		text = "-" + text
+	}
+	return text
+}
+
+// magnitude returns m such that 10^m <= value < 10^(m+1), for a positive value
+func magnitude(value *big.Rat) int {
+	integer := new(big.Int).Quo(value.Num(), value.Denom())
+	if integer.Sign() > 0 {
+		return len(integer.String()) - 1
+	}
+	m, scaled := 0, new(big.Rat).Set(value)
+	for scaled.Cmp(big.NewRat(1, 1)) < 0 {
+		scaled.Mul(scaled, big.NewRat(10, 1))
+		m--
+	}
+	return m
+}
+
+// exactDecimals returns the decimals of a terminating value, or MaxDecimals
+func exactDecimals(value *big.Rat) int {
+	denominator := new(big.Int).Set(value.Denom())
+	twos, fives := divideOut(denominator, 2), divideOut(denominator, 5)
+	if denominator.Cmp(big.NewInt(1)) != 0 || twos > MaxDecimals || fives > MaxDecimals {
+		return MaxDecimals
+	}
+	if twos > fives {
+		return twos
+	}
+	return fives
+}
+
+// divideOut divides n by the factor as many times as it can and returns how many
+func divideOut(n *big.Int, factor int64) int {
+	count, f, q, m := 0, big.NewInt(factor), new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(n, f, m)
+		if m.Sign() != 0 {
+			return count
+		}
+		n.Set(q)
+		count++
+	}
+}
+
+// round returns value·10^decimals rounded half up, decimals may be negative
+func round(value *big.Rat, decimals int) *big.Int {
+	numerator, denominator := new(big.Int).Set(value.Num()), new(big.Int).Set(value.Denom())
+	if decimals >= 0 {
+		numerator.Mul(numerator, new(big.Int).Exp(ten, big.NewInt(int64(decimals)), nil))
+	} else {
+		denominator.Mul(denominator, new(big.Int).Exp(ten, big.NewInt(int64(-decimals)), nil))
+	}
+	// floor((2n + d) / 2d)
+	numerator.Mul(numerator, big.NewInt(2)).Add(numerator, denominator)
+	return numerator.Quo(numerator, denominator.Mul(denominator, big.NewInt(2)))
+}
+
+// render writes digits·10^-decimals
+func render(digits *big.Int, decimals int, trim bool, separator string) string {
+	text := digits.String()
+	if decimals < 0 {
+		if digits.Sign() != 0 {
+			text += strings.Repeat("0", -decimals)
+		}
+		decimals = 0
+	}
+	if len(text) <= decimals {
+		text = strings.Repeat("0", decimals-len(text)+1) + text
+	}
+	integer, fraction := text[:len(text)-decimals], text[len(text)-decimals:]
+	if trim {
+		fraction = strings.TrimRight(fraction, "0")
+	}
+	if separator != "" {
+		integer = group(integer, separator)
+	}
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
+// group separates the thousands of the digits
+func group(digits, separator string) string {
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
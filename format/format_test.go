@@ -0,0 +1,68 @@
+package format_test
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/format"
+)
+
+var (
+	weth = core.WETH9[1]
+	usdc = core.NewToken(1, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), 6, "USDC", "USD Coin")
+)
+
+func TestFraction(t *testing.T) {
+	var tests = []struct {
+		numerator, denominator int64
+		opts                   *format.Options
+		expect                 string
+	}{
+		{15, 10, nil, "1.5"},
+		{1, 3, nil, "0.333333333333333333333333333333333333"},
+		{0, 1, nil, "0"},
+		{0, 1, &format.Options{Decimals: 2}, "0.00"},
+		{1234567, 1, &format.Options{ThousandsSeparator: ","}, "1,234,567"},
+		{1234567891, 1000, &format.Options{Decimals: 2, ThousandsSeparator: ","}, "1,234,567.89"},
+		{12345, 1000, &format.Options{Decimals: 2}, "12.35"},
+		{-12345, 1000, &format.Options{Decimals: 2}, "-12.35"},
+		{-1, 1000, &format.Options{Decimals: 2}, "0.00"},
+		{2, 3, &format.Options{SignificantDigits: 4}, "0.6667"},
+		{12345, 100000000, &format.Options{SignificantDigits: 2}, "0.00012"},
+		{9996, 1000, &format.Options{SignificantDigits: 3}, "10"},
+		{1234567, 1, &format.Options{SignificantDigits: 3, ThousandsSeparator: " "}, "1 230 000"},
+		{15, 10, &format.Options{SignificantDigits: 6}, "1.5"},
+		{1, 0, nil, "NaN"},
+	}
+	for i, test := range tests {
+		output := format.Fraction(big.NewInt(test.numerator), big.NewInt(test.denominator), test.opts)
+		if output != test.expect {
+			t.Errorf("test #%d: expect[%s], but got[%s]", i, test.expect, output)
+		}
+	}
+}
+
+func TestAmountPricePercent(t *testing.T) {
+	amount := core.FromRawAmount(weth, big.NewInt(1500000000000000000))
+	if output := format.Amount(amount, &format.Options{Decimals: format.Exact, Symbol: true}); output != "1.5 WETH" {
+		t.Errorf("expect[1.5 WETH], but got[%s]", output)
+	}
+	if output := format.Amount(core.FromRawAmount(usdc, big.NewInt(1234567891)), &format.Options{Decimals: 2, ThousandsSeparator: ","}); output != "1,234.57" {
+		t.Errorf("expect[1,234.57], but got[%s]", output)
+	}
+
+	// 1 WETH for 1812.5 USDC, in raw units
+	price := core.NewPrice(weth, usdc, big.NewInt(1000000000000000000), big.NewInt(1812500000))
+	if output := format.Price(price, &format.Options{SignificantDigits: 6, Symbol: true}); output != "1812.5 USDC/WETH" {
+		t.Errorf("expect[1812.5 USDC/WETH], but got[%s]", output)
+	}
+	if output := format.Price(price.Invert(), nil); output != "0.000551724" {
+		t.Errorf("expect[0.000551724], but got[%s]", output)
+	}
+
+	if output := format.Percent(core.NewPercent(big.NewInt(1), big.NewInt(300)), nil); output != "0.33%" {
+		t.Errorf("expect[0.33%%], but got[%s]", output)
+	}
+}
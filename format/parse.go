@@ -0,0 +1,76 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+)
+
+var (
+	ErrInvalidAmount   = errors.New("invalid decimal amount")
+	ErrExcessPrecision = errors.New("amount has more decimals than its currency")
+	ErrAmountTooLarge  = errors.New("amount exceeds uint256")
+	ErrUnknownSymbol   = errors.New("unknown currency symbol")
+)
+
+// ParseAmount parses a non-negative decimal string, e.g. "1.5", into an amount of the currency.
+// Thousands separators and exponents are not accepted.
+// Returns ErrExcessPrecision if it has more decimals than the currency, other than trailing zeros.
+func ParseAmount(currency core.Currency, s string) (*core.CurrencyAmount, error) {
+	text := strings.TrimSpace(s)
+	integer, fraction := text, ""
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		integer, fraction = text[:i], text[i+1:]
+	}
+	if integer == "" && fraction == "" || !digits(integer) || !digits(fraction) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	decimals := int(currency.Decimals())
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("%w: %q has %d decimals, %s has %d", ErrExcessPrecision, s, len(fraction), currency.Symbol(), decimals)
+	}
+	raw, ok := new(big.Int).SetString(integer+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if raw.Cmp(core.MaxUint256) > 0 {
+		return nil, fmt.Errorf("%w: %q", ErrAmountTooLarge, s)
+	}
+	return core.FromRawAmount(currency, raw), nil
+}
+
+// ParseAmountOf parses an amount followed by the symbol of one of the currencies, e.g. "1.5 WETH".
+// Symbols are matched case-sensitively first, then case-insensitively if that is unambiguous.
+func ParseAmountOf(s string, currencies ...core.Currency) (*core.CurrencyAmount, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%w: %q is not an amount and a symbol", ErrInvalidAmount, s)
+	}
+	var folded []core.Currency
+	for _, currency := range currencies {
+		if currency.Symbol() == fields[1] {
+			return ParseAmount(currency, fields[0])
+		}
+		if strings.EqualFold(currency.Symbol(), fields[1]) {
+			folded = append(folded, currency)
+		}
+	}
+	if len(folded) != 1 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, fields[1])
+	}
+	return ParseAmount(folded[0], fields[0])
+}
+
+// digits tells whether the string only has ASCII digits
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package format_test
+
+import (
+	"errors"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/format"
+)
+
+func TestParseAmount(t *testing.T) {
+	var tests = []struct {
+		currency core.Currency
+		input    string
+		expect   string
+		err      error
+	}{
+		{weth, "1.5", "1500000000000000000", nil},
+		{weth, " 0.000000000000000001 ", "1", nil},
+		{usdc, "1234.560000", "1234560000", nil},
+		{usdc, ".5", "500000", nil},
+		{usdc, "7", "7000000", nil},
+		{usdc, "0.0000001", "", format.ErrExcessPrecision},
+		{usdc, "1,000", "", format.ErrInvalidAmount},
+		{usdc, "-1", "", format.ErrInvalidAmount},
+		{usdc, "1e6", "", format.ErrInvalidAmount},
+		{usdc, ".", "", format.ErrInvalidAmount},
+		{usdc, "", "", format.ErrInvalidAmount},
+		{weth, "1000000000000000000000000000000000000000000000000000000000000", "", format.ErrAmountTooLarge},
+	}
+	for i, test := range tests {
+		output, err := format.ParseAmount(test.currency, test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test #%d: expect[%v], but got[%v]", i, test.err, err)
+			continue
+		}
+		if err == nil && output.Quotient().String() != test.expect {
+			t.Errorf("test #%d: expect[%s], but got[%s]", i, test.expect, output.Quotient())
+		}
+	}
+
+	// round trips
+	amount, err := format.ParseAmount(usdc, "1234.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := format.Amount(amount, nil); output != "1234.5" {
+		t.Errorf("expect[1234.5], but got[%s]", output)
+	}
+}
+
+func TestParseAmountOf(t *testing.T) {
+	amount, err := format.ParseAmountOf("1.5 WETH", usdc, weth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !amount.Currency.Equal(weth) || amount.Quotient().String() != "1500000000000000000" {
+		t.Errorf("expect[1.5 WETH], but got[%s %s]", amount.Quotient(), amount.Currency.Symbol())
+	}
+	if amount, err = format.ParseAmountOf("2 usdc", usdc, weth); err != nil || !amount.Currency.Equal(usdc) {
+		t.Errorf("expect[2 USDC], but got[%v]", err)
+	}
+	if _, err := format.ParseAmountOf("2 DAI", usdc, weth); !errors.Is(err, format.ErrUnknownSymbol) {
+		t.Errorf("expect[%v], but got[%v]", format.ErrUnknownSymbol, err)
+	}
+	if _, err := format.ParseAmountOf("2", usdc); !errors.Is(err, format.ErrInvalidAmount) {
+		t.Errorf("expect[%v], but got[%v]", format.ErrInvalidAmount, err)
+	}
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+const ERC20ABI = "[ { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"owner\", \"type\": \"address\" }, { \"internalType\": \"address\", \"name\": \"spender\", \"type\": \"address\" } ], \"name\": \"allowance\", \"outputs\": [ { \"internalType\": \"uint256\", \"name\": \"\", \"type\": \"uint256\" } ], \"stateMutability\": \"view\", \"type\": \"function\" }, { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"spender\", \"type\": \"address\" }, { \"internalType\": \"uint256\", \"name\": \"amount\", \"type\": \"uint256\" } ], \"name\": \"approve\", \"outputs\": [ { \"internalType\": \"bool\", \"name\": \"\", \"type\": \"bool\" } ], \"stateMutability\": \"nonpayable\", \"type\": \"function\" }, { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"account\", \"type\": \"address\" } ], \"name\": \"balanceOf\", \"outputs\": [ { \"internalType\": \"uint256\", \"name\": \"\", \"type\": \"uint256\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+
+// ApproveGas is the gas limit of an approve call
+const ApproveGas uint64 = 60000
+
+// ApprovalPolicy decides the amount to approve
+type ApprovalPolicy int
+
+const (
+	ApproveExact     ApprovalPolicy = iota // Approve the required amount only
+	ApproveUnlimited                       // Approve the maximum uint256 so later calls need no approval
+)
+
+// Call is a step of an ordered call list
+type Call struct {
+	To    common.Address // The contract to call
+	Data  []byte         // The calldata
+	Value *big.Int       // The amount of wei to send
+	Gas   uint64         // Gas limit estimate of the call
+}
+
+// ApprovalOptions for planning approvals
+type ApprovalOptions struct {
+	Policy      ApprovalPolicy
+	ResetToZero bool // Approve zero before changing a non zero allowance, as USDT-like tokens require
+}
+
+// PlanOptions for producing ordered call lists of approvals followed by router calls
+type PlanOptions struct {
+	Router     common.Address              // Address of the router, the spender of the approvals
+	Allowances map[common.Address]*big.Int // Current allowance of the router per token. A missing token has none.
+	Approval   ApprovalOptions
+	Gas        GasEstimator // Optional. Defaults to DefaultGasTable.
+}
+
+// ApproveCalldata returns the calldata of approve(spender, amount)
+func ApproveCalldata(spender common.Address, amount *big.Int) ([]byte, error) {
+	erc20ABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return erc20ABI.Pack("approve", spender, amount)
+}
+
+// AllowanceCalldata returns the calldata of allowance(owner, spender)
+func AllowanceCalldata(owner, spender common.Address) ([]byte, error) {
+	erc20ABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return erc20ABI.Pack("allowance", owner, spender)
+}
+
+// UnpackAllowance decodes the result of an allowance call
+func UnpackAllowance(result []byte) (*big.Int, error) {
+	erc20ABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	values, err := erc20ABI.Unpack("allowance", result)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// ApprovalCalls returns the approve calls needed for the spender to spend the amount, given its current allowance.
+// Returns nothing for the native currency or if the allowance is sufficient.
+func ApprovalCalls(amount *core.CurrencyAmount, allowance *big.Int, spender common.Address, options ApprovalOptions) ([]*Call, error) {
+	if amount.Currency.IsNative() {
+		return nil, nil
+	}
+	if allowance == nil {
+		allowance = big.NewInt(0)
+	}
+	required := amount.Quotient()
+	if allowance.Cmp(required) >= 0 {
+		return nil, nil
+	}
+
+	token := entities.WrappedOf(amount.Currency).Address
+	var calls []*Call
+	if options.ResetToZero && allowance.Sign() > 0 {
+		data, err := ApproveCalldata(spender, big.NewInt(0))
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, &Call{To: token, Data: data, Value: big.NewInt(0), Gas: ApproveGas})
+	}
+	approved := required
+	if options.Policy == ApproveUnlimited {
+		approved = core.MaxUint256
+	}
+	data, err := ApproveCalldata(spender, approved)
+	if err != nil {
+		return nil, err
+	}
+	return append(calls, &Call{To: token, Data: data, Value: big.NewInt(0), Gas: ApproveGas}), nil
+}
+
+// Call packs the router call into a step sent to the router
+func (p *MethodParameters) Call(router common.Address, gas GasEstimator) (*Call, error) {
+	if gas == nil {
+		gas = DefaultGasTable
+	}
+	data, err := p.Pack()
+	if err != nil {
+		return nil, err
+	}
+	limit, err := gas.GasLimit(p)
+	if err != nil {
+		return nil, err
+	}
+	value := p.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return &Call{To: router, Data: data, Value: value, Gas: limit}, nil
+}
+
+// plan returns the approvals of the amounts followed by the router calls
+func plan(amounts []*core.CurrencyAmount, methods []*MethodParameters, options PlanOptions) ([]*Call, error) {
+	var calls []*Call
+	for _, amount := range amounts {
+		approvals, err := ApprovalCalls(amount, options.Allowances[entities.WrappedOf(amount.Currency).Address], options.Router, options.Approval)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, approvals...)
+	}
+	for _, method := range methods {
+		call, err := method.Call(options.Router, options.Gas)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// PlanSwap returns the calls executing the trade: the approval of the input token if needed, then the swap
+func PlanSwap(trade *entities.Trade, tradeOptions TradeOptions, options PlanOptions) ([]*Call, error) {
+	params, err := SwapCallParameters(trade, tradeOptions)
+	if err != nil {
+		return nil, err
+	}
+	slippage, err := slippageOrZero(tradeOptions.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	amountIn, err := trade.MaximumAmountIn(slippage)
+	if err != nil {
+		return nil, err
+	}
+	if tradeOptions.WrappedInput {
+		amountIn = entities.WrappedAmount(amountIn)
+	}
+	return plan([]*core.CurrencyAmount{amountIn}, []*MethodParameters{params}, options)
+}
+
+// PlanZapIn returns the calls of a single sided deposit: the approvals of both tokens if needed,
+// then the swap and the addLiquidity
+func PlanZapIn(zap *entities.ZapInAmounts, liquidityOptions LiquidityOptions, options PlanOptions) ([]*Call, error) {
+	methods, err := ZapInCallParameters(zap, liquidityOptions)
+	if err != nil {
+		return nil, err
+	}
+	slippage, err := slippageOrZero(liquidityOptions.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	amountA := zap.SwapAmount.Add(zap.AmountA)
+	amountB := core.FromRawAmount(zap.AmountB.Currency, minimumAmount(zap.SwapOutput.Quotient(), slippage))
+	return plan([]*core.CurrencyAmount{amountA, amountB}, methods, options)
+}
+
+// PlanZapOut returns the calls of a withdrawal into a single currency: the approvals of the liquidity token
+// and of the swapped token if needed, then the removeLiquidity and the swap
+func PlanZapOut(zap *entities.ZapOutAmounts, liquidityOptions LiquidityOptions, options PlanOptions) ([]*Call, error) {
+	methods, err := ZapOutCallParameters(zap, liquidityOptions)
+	if err != nil {
+		return nil, err
+	}
+	slippage, err := slippageOrZero(liquidityOptions.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	amountB := core.FromRawAmount(zap.AmountB.Currency, minimumAmount(zap.AmountB.Quotient(), slippage))
+	return plan([]*core.CurrencyAmount{zap.Liquidity, amountB}, methods, options)
+}
@@ -0,0 +1,150 @@
+package router_test
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestApproveCalldata(t *testing.T) {
+	testNumber = 0
+	data, err := router.ApproveCalldata(routerAddress, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x095ea7b30000000000000000000000007a250d5630b4cf539739df2c5dacb4c659f2488d"+
+		"0000000000000000000000000000000000000000000000000000000000000001", hexutil.Encode(data))
+
+	data, err = router.AllowanceCalldata(recipient, routerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0xdd62ed3e", hexutil.Encode(data[:4]))
+
+	allowance, err := router.UnpackAllowance(common.LeftPadBytes(big.NewInt(42).Bytes(), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, big.NewInt(42), allowance)
+}
+
+func TestApprovalCalls(t *testing.T) {
+	testNumber = 0
+	amount := core.FromRawAmount(token0, big.NewInt(100))
+
+	// sufficient allowance
+	calls, err := router.ApprovalCalls(amount, big.NewInt(100), routerAddress, router.ApprovalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, 0, len(calls))
+
+	// native currency needs no approval
+	calls, _ = router.ApprovalCalls(core.FromRawAmount(ether, big.NewInt(100)), nil, routerAddress, router.ApprovalOptions{})
+	check(t, 0, len(calls))
+
+	// exact amount
+	calls, _ = router.ApprovalCalls(amount, big.NewInt(10), routerAddress, router.ApprovalOptions{})
+	exact, _ := router.ApproveCalldata(routerAddress, big.NewInt(100))
+	check(t, 1, len(calls))
+	check(t, token0.Address, calls[0].To)
+	check(t, exact, calls[0].Data)
+	check(t, router.ApproveGas, calls[0].Gas)
+
+	// unlimited amount, reset to zero first
+	calls, _ = router.ApprovalCalls(amount, big.NewInt(10), routerAddress, router.ApprovalOptions{
+		Policy:      router.ApproveUnlimited,
+		ResetToZero: true,
+	})
+	zero, _ := router.ApproveCalldata(routerAddress, big.NewInt(0))
+	unlimited, _ := router.ApproveCalldata(routerAddress, core.MaxUint256)
+	check(t, 2, len(calls))
+	check(t, zero, calls[0].Data)
+	check(t, unlimited, calls[1].Data)
+
+	// no reset when there is no allowance
+	calls, _ = router.ApprovalCalls(amount, big.NewInt(0), routerAddress, router.ApprovalOptions{ResetToZero: true})
+	check(t, 1, len(calls))
+}
+
+func TestPlanSwap(t *testing.T) {
+	testNumber = 0
+	route, err := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactOut(route, core.FromRawAmount(token1, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tradeOptions := router.TradeOptions{AllowedSlippage: slippage, Recipient: recipient, Deadline: deadline}
+	calls, err := router.PlanSwap(trade, tradeOptions, router.PlanOptions{Router: routerAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxAmountIn, _ := trade.MaximumAmountIn(slippage)
+	approve, _ := router.ApproveCalldata(routerAddress, maxAmountIn.Quotient())
+	_, swap, _ := router.SwapCallParametersPacked(trade, tradeOptions)
+	check(t, 2, len(calls))
+	check(t, approve, calls[0].Data)
+	check(t, routerAddress, calls[1].To)
+	check(t, swap, calls[1].Data)
+
+	// already approved
+	calls, _ = router.PlanSwap(trade, tradeOptions, router.PlanOptions{
+		Router:     routerAddress,
+		Allowances: map[common.Address]*big.Int{token0.Address: core.MaxUint256},
+	})
+	check(t, 1, len(calls))
+}
+
+func TestPlanZap(t *testing.T) {
+	testNumber = 0
+	million := big.NewInt(1000000)
+	pair, _ := entities.NewPair(core.FromRawAmount(token0, million), core.FromRawAmount(token1, million), nil)
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	liquidityOptions := router.LiquidityOptions{AllowedSlippage: slippage, Recipient: recipient, Deadline: deadline}
+
+	{
+		zap, err := pair.GetZapInAmounts(totalSupply, core.FromRawAmount(token0, big.NewInt(10000)), false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls, err := router.PlanZapIn(zap, liquidityOptions, router.PlanOptions{Router: routerAddress})
+		if err != nil {
+			t.Fatal(err)
+		}
+		approve0, _ := router.ApproveCalldata(routerAddress, big.NewInt(10000))
+		approve1, _ := router.ApproveCalldata(routerAddress, big.NewInt(4905))
+		check(t, 4, len(calls))
+		check(t, token0.Address, calls[0].To)
+		check(t, approve0, calls[0].Data)
+		check(t, token1.Address, calls[1].To)
+		check(t, approve1, calls[1].Data)
+		check(t, routerAddress, calls[2].To)
+		check(t, routerAddress, calls[3].To)
+	}
+
+	{
+		zap, err := pair.GetZapOutAmounts(token0, totalSupply, core.FromRawAmount(pair.LiquidityToken, big.NewInt(10000)), false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls, err := router.PlanZapOut(zap, liquidityOptions, router.PlanOptions{
+			Router:     routerAddress,
+			Allowances: map[common.Address]*big.Int{token1.Address: core.MaxUint256},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		approve, _ := router.ApproveCalldata(routerAddress, big.NewInt(10000))
+		check(t, 3, len(calls))
+		check(t, pair.LiquidityToken.Address, calls[0].To)
+		check(t, approve, calls[0].Data)
+	}
+}
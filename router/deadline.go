@@ -0,0 +1,95 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// DefaultTTL is the lifetime of a call without a deadline
+	DefaultTTL = 5 * time.Minute
+	// DefaultMaxTTL is the furthest a deadline may be in the future unless the options allow more
+	DefaultMaxTTL = 24 * time.Hour
+)
+
+var (
+	ErrDeadlinePast   = errors.New("deadline has already passed")
+	ErrDeadlineTooFar = errors.New("deadline is too far in the future")
+	ErrInvalidTTL     = errors.New("invalid deadline ttl")
+)
+
+// Clock tells the current time. It lets deadlines be computed deterministically, e.g. in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock of the local system time
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a Clock that always tells the time t
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// deadlineOptions describe when a router call expires, see TradeOptions
+type deadlineOptions struct {
+	Deadline     *big.Int
+	TTL          time.Duration
+	DeadlineBase *big.Int
+	Clock        Clock
+	MaxTTL       time.Duration
+}
+
+// resolveDeadline returns the deadline in epoch seconds. Without an absolute deadline, it is the ttl after
+// the block timestamp base or after the clock time.
+// Fails if the deadline is before the reference time or further than the maximum ttl after it, and with
+// ErrInvalidTTL if the ttl or the maximum ttl is negative or below a second.
+func resolveDeadline(options deadlineOptions) (*big.Int, error) {
+	now := options.DeadlineBase
+	if now == nil {
+		clock := options.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		now = big.NewInt(clock.Now().Unix())
+	}
+	maxTTL := options.MaxTTL
+	if maxTTL == 0 {
+		maxTTL = DefaultMaxTTL
+	}
+	if maxTTL < time.Second {
+		return nil, fmt.Errorf("%w: max ttl %s", ErrInvalidTTL, maxTTL)
+	}
+
+	deadline := options.Deadline
+	if deadline == nil {
+		ttl := options.TTL
+		if ttl == 0 {
+			ttl = DefaultTTL
+		}
+		// deadlines are in whole seconds, a shorter ttl would expire immediately
+		if ttl < time.Second {
+			return nil, fmt.Errorf("%w: ttl %s", ErrInvalidTTL, ttl)
+		}
+		deadline = big.NewInt(0).Add(now, big.NewInt(int64(ttl/time.Second)))
+	}
+
+	if deadline.Cmp(now) < 0 {
+		return nil, fmt.Errorf("%w: %s < %s", ErrDeadlinePast, deadline, now)
+	}
+	latest := big.NewInt(0).Add(now, big.NewInt(int64(maxTTL/time.Second)))
+	if deadline.Cmp(latest) > 0 {
+		return nil, fmt.Errorf("%w: %s > %s", ErrDeadlineTooFar, deadline, latest)
+	}
+	return deadline, nil
+}
@@ -0,0 +1,104 @@
+package router_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestSwapCallParametersDeadline(t *testing.T) {
+	testNumber = 0
+	route, err := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(token0, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1600000000, 0)
+	clock := router.FixedClock(now)
+
+	// defaults to five minutes from the clock time
+	{
+		params, err := router.SwapCallParameters(trade, router.TradeOptions{Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, big.NewInt(1600000300), params.Args[4])
+	}
+
+	// relative to the clock time
+	{
+		params, err := router.SwapCallParameters(trade, router.TradeOptions{Clock: clock, TTL: time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, big.NewInt(1600000060), params.Args[4])
+	}
+
+	// relative to a block timestamp
+	{
+		params, err := router.SwapCallParameters(trade, router.TradeOptions{
+			Clock:        clock,
+			TTL:          time.Minute,
+			DeadlineBase: big.NewInt(1700000000),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, big.NewInt(1700000060), params.Args[4])
+	}
+
+	// rejects past deadlines
+	{
+		_, err := router.SwapCallParameters(trade, router.TradeOptions{Clock: clock, Deadline: big.NewInt(1599999999)})
+		check(t, true, errors.Is(err, router.ErrDeadlinePast))
+	}
+
+	// rejects deadlines too far in the future
+	{
+		_, err := router.SwapCallParameters(trade, router.TradeOptions{Clock: clock, Deadline: big.NewInt(1600000000000)})
+		check(t, true, errors.Is(err, router.ErrDeadlineTooFar))
+
+		_, err = router.SwapCallParameters(trade, router.TradeOptions{Clock: clock, TTL: time.Hour, MaxTTL: time.Minute})
+		check(t, true, errors.Is(err, router.ErrDeadlineTooFar))
+	}
+
+	// rejects negative and sub-second ttls
+	{
+		for _, options := range []router.TradeOptions{
+			{Clock: clock, TTL: -time.Minute},
+			{Clock: clock, TTL: 500 * time.Millisecond},
+			{Clock: clock, MaxTTL: -time.Minute},
+			{Clock: clock, MaxTTL: time.Millisecond},
+		} {
+			_, err := router.SwapCallParameters(trade, options)
+			check(t, true, errors.Is(err, router.ErrInvalidTTL))
+		}
+	}
+
+	// liquidity calls use the same deadline options
+	{
+		million := big.NewInt(1000000)
+		pair, _ := entities.NewPair(core.FromRawAmount(token0, million), core.FromRawAmount(token1, million), nil)
+		zap, err := pair.GetZapInAmounts(core.FromRawAmount(pair.LiquidityToken, million), core.FromRawAmount(token0, big.NewInt(10000)), false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls, err := router.ZapInCallParameters(zap, router.LiquidityOptions{Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, big.NewInt(1600000300), calls[0].Args[4])
+		check(t, big.NewInt(1600000300), calls[1].Args[7])
+
+		_, err = router.ZapInCallParameters(zap, router.LiquidityOptions{Clock: clock, Deadline: big.NewInt(1)})
+		check(t, true, errors.Is(err, router.ErrDeadlinePast))
+	}
+}
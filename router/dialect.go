@@ -0,0 +1,63 @@
+package router
+
+import (
+	"strings"
+)
+
+// Dialect is the naming of a Router02 fork. Forks on other chains say their native currency where Uniswap says
+// ETH, e.g. swapExactAVAXForTokens and WAVAX() of Trader Joe, with the same arguments and behavior.
+// A nil Dialect is UniswapV2.
+type Dialect struct {
+	Name   string
+	Native string // The word for the native currency in method names, e.g. "ETH" or "AVAX"
+}
+
+var (
+	UniswapV2 = &Dialect{Name: "Uniswap V2", Native: "ETH"}
+	TraderJoe = &Dialect{Name: "Trader Joe", Native: "AVAX"}
+	Pangolin  = &Dialect{Name: "Pangolin", Native: "AVAX"}
+)
+
+// Dialects of known Router02 forks by name. Forks that kept the ETH names, e.g. SushiSwap, PancakeSwap or
+// QuickSwap, speak UniswapV2 on every chain.
+var Dialects = map[string]*Dialect{
+	"uniswap":     UniswapV2,
+	"sushiswap":   UniswapV2,
+	"pancakeswap": UniswapV2,
+	"quickswap":   UniswapV2,
+	"spookyswap":  UniswapV2,
+	"traderjoe":   TraderJoe,
+	"pangolin":    Pangolin,
+}
+
+func (d *Dialect) native() string {
+	if d == nil || d.Native == "" {
+		return UniswapV2.Native
+	}
+	return d.Native
+}
+
+// MethodName returns the name in the dialect of a Router02 method, e.g. swapExactAVAXForTokens of
+// swapExactETHForTokens
+func (d *Dialect) MethodName(name string) string {
+	return strings.ReplaceAll(name, UniswapV2.Native, d.native())
+}
+
+// Canonical returns the Router02 name of a method of the dialect, e.g. swapExactETHForTokens of
+// swapExactAVAXForTokens
+func (d *Dialect) Canonical(name string) string {
+	return strings.ReplaceAll(name, d.native(), UniswapV2.Native)
+}
+
+// ABI returns V2Router02ABI with the method and argument names of the dialect
+func (d *Dialect) ABI() string {
+	return strings.ReplaceAll(V2Router02ABI, UniswapV2.Native, d.native())
+}
+
+// translate renames the Router02 calls into the dialect
+func (d *Dialect) translate(calls ...*MethodParameters) []*MethodParameters {
+	for _, call := range calls {
+		call.MethodName, call.Dialect = d.MethodName(call.MethodName), d
+	}
+	return calls
+}
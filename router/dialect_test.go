@@ -0,0 +1,74 @@
+package router_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestDialect(t *testing.T) {
+	testNumber = 0
+	avax, err := entities.NativeOnChain(43114)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joe := core.NewToken(43114, common.HexToAddress("0x6e84a6216eA6dACC71eE8E6b0a5B7322EEbC0fDd"), 18, "JOE", "JoeToken")
+	pair, err := entities.NewPair(core.FromRawAmount(avax.Wrapped(), big.NewInt(1000)), core.FromRawAmount(joe, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, err := entities.NewRoute([]*entities.Pair{pair}, avax, joe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(avax, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.SwapCallParameters(trade, router.TradeOptions{
+		AllowedSlippage: slippage,
+		Recipient:       recipient,
+		Deadline:        deadline,
+		Dialect:         router.Dialects["traderjoe"],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "swapExactAVAXForTokens", params.MethodName)
+	check(t, []common.Address{avax.Wrapped().Address, joe.Address}, params.Args[1])
+	check(t, big.NewInt(100), params.Value)
+
+	data, err := params.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector := crypto.Keccak256([]byte("swapExactAVAXForTokens(uint256,address[],address,uint256)"))[:4]
+	check(t, true, bytes.Equal(selector, data[:4]))
+
+	// the calldata decodes in the dialect only
+	if _, err := router.UnpackMethodParameters(data, params.Value); err == nil {
+		t.Errorf("expect[%v], but got[nil]", router.ErrUnknownMethod)
+	}
+	decoded, err := router.TraderJoe.Unpack(data, params.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "swapExactAVAXForTokens", decoded.MethodName)
+	swap, err := router.ApplySwap(decoded, []*entities.Pair{pair}, avax.Wrapped().Address, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, true, swap.EtherIn && !swap.Reverted())
+	check(t, trade.OutputAmount().Quotient(), swap.AmountOut())
+
+	gas, _ := router.DefaultGasTable.GasLimit(params)
+	check(t, uint64(80000+80000), gas)
+	check(t, "removeLiquidityETH", router.Pangolin.Canonical("removeLiquidityAVAX"))
+	check(t, "WETH", (*router.Dialect)(nil).MethodName("WETH"))
+}
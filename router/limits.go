@@ -0,0 +1,96 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var (
+	ErrPriceImpactTooHigh = errors.New("price impact exceeds the limit")
+	ErrTooManyHops        = errors.New("number of hops exceeds the limit")
+	ErrOutputTooLow       = errors.New("output is below the limit")
+	ErrLimitCurrency      = errors.New("minimum output currency differs from the trade output")
+)
+
+// PriceImpactError reports a trade whose price impact exceeds TradeOptions.MaxPriceImpact
+type PriceImpactError struct {
+	Limit  *core.Percent
+	Actual *core.Percent
+}
+
+func (e *PriceImpactError) Error() string {
+	return fmt.Sprintf("%s: %s%% > %s%% by %s%%", ErrPriceImpactTooHigh,
+		e.Actual.ToFixed(2), e.Limit.ToFixed(2), e.Excess().ToFixed(2))
+}
+
+func (e *PriceImpactError) Unwrap() error {
+	return ErrPriceImpactTooHigh
+}
+
+// Excess returns how much the price impact exceeds the limit
+func (e *PriceImpactError) Excess() *core.Percent {
+	return e.Actual.Subtract(e.Limit)
+}
+
+// HopsError reports a trade with more hops than TradeOptions.MaxHops
+type HopsError struct {
+	Limit  int
+	Actual int
+}
+
+func (e *HopsError) Error() string {
+	return fmt.Sprintf("%s: %d > %d by %d", ErrTooManyHops, e.Actual, e.Limit, e.Excess())
+}
+
+func (e *HopsError) Unwrap() error {
+	return ErrTooManyHops
+}
+
+// Excess returns how many hops exceed the limit
+func (e *HopsError) Excess() int {
+	return e.Actual - e.Limit
+}
+
+// OutputError reports a trade whose slippage adjusted output is below TradeOptions.MinimumOutput
+type OutputError struct {
+	Limit  *core.CurrencyAmount
+	Actual *core.CurrencyAmount
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("%s: %s < %s %s by %s", ErrOutputTooLow, e.Actual.ToExact(), e.Limit.ToExact(),
+		e.Limit.Currency.Symbol(), e.Shortfall().ToExact())
+}
+
+func (e *OutputError) Unwrap() error {
+	return ErrOutputTooLow
+}
+
+// Shortfall returns how much the output is below the limit
+func (e *OutputError) Shortfall() *core.CurrencyAmount {
+	return core.FromRawAmount(e.Limit.Currency, big.NewInt(0).Sub(e.Limit.Quotient(), e.Actual.Quotient()))
+}
+
+// checkLimits returns an error if the trade breaches a limit of the options
+// @param minAmountOut the slippage adjusted output of the trade
+func checkLimits(trade *entities.Trade, options TradeOptions, minAmountOut *core.CurrencyAmount) error {
+	if hops := len(trade.Route.Pairs); options.MaxHops > 0 && hops > options.MaxHops {
+		return &HopsError{Limit: options.MaxHops, Actual: hops}
+	}
+	if options.MaxPriceImpact != nil && trade.PriceImpact.GreaterThan(options.MaxPriceImpact.Fraction) {
+		return &PriceImpactError{Limit: options.MaxPriceImpact, Actual: trade.PriceImpact}
+	}
+	if options.MinimumOutput != nil {
+		if !options.MinimumOutput.Currency.Equal(minAmountOut.Currency) {
+			return ErrLimitCurrency
+		}
+		if minAmountOut.Quotient().Cmp(options.MinimumOutput.Quotient()) < 0 {
+			return &OutputError{Limit: options.MinimumOutput, Actual: minAmountOut}
+		}
+	}
+	return nil
+}
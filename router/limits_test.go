@@ -0,0 +1,85 @@
+package router_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestSwapCallParametersLimits(t *testing.T) {
+	route, err := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1}, ether, token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := func() router.TradeOptions {
+		return router.TradeOptions{
+			AllowedSlippage: slippage,
+			Recipient:       recipient,
+			Deadline:        deadline,
+		}
+	}
+
+	// within limits
+	{
+		opts := options()
+		opts.MaxHops = 2
+		opts.MaxPriceImpact = core.NewPercent(big.NewInt(20), big.NewInt(100))
+		opts.MinimumOutput = core.FromRawAmount(token1, big.NewInt(81))
+		if _, err := router.SwapCallParameters(trade, opts); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// too many hops
+	{
+		opts := options()
+		opts.MaxHops = 1
+		_, err := router.SwapCallParameters(trade, opts)
+		var hopsErr *router.HopsError
+		if !errors.Is(err, router.ErrTooManyHops) || !errors.As(err, &hopsErr) || hopsErr.Excess() != 1 {
+			t.Errorf("expect[%+v], but got[%+v]", router.ErrTooManyHops, err)
+		}
+	}
+
+	// price impact too high
+	{
+		opts := options()
+		opts.MaxPriceImpact = core.NewPercent(big.NewInt(5), big.NewInt(100))
+		_, err := router.SwapCallParameters(trade, opts)
+		var impactErr *router.PriceImpactError
+		if !errors.Is(err, router.ErrPriceImpactTooHigh) || !errors.As(err, &impactErr) {
+			t.Fatalf("expect[%+v], but got[%+v]", router.ErrPriceImpactTooHigh, err)
+		}
+		if impactErr.Excess().ToFixed(2) != "13.00" {
+			t.Errorf("expect[13.00], but got[%s]", impactErr.Excess().ToFixed(2))
+		}
+	}
+
+	// output too low
+	{
+		opts := options()
+		opts.MinimumOutput = core.FromRawAmount(token1, big.NewInt(100))
+		_, err := router.SwapCallParameters(trade, opts)
+		var outputErr *router.OutputError
+		if !errors.Is(err, router.ErrOutputTooLow) || !errors.As(err, &outputErr) {
+			t.Fatalf("expect[%+v], but got[%+v]", router.ErrOutputTooLow, err)
+		}
+		if outputErr.Shortfall().Quotient().Cmp(big.NewInt(19)) != 0 {
+			t.Errorf("expect[19], but got[%s]", outputErr.Shortfall().Quotient())
+		}
+
+		opts.MinimumOutput = core.FromRawAmount(token0, big.NewInt(1))
+		_, err = router.SwapCallParameters(trade, opts)
+		if err != router.ErrLimitCurrency {
+			t.Errorf("expect[%+v], but got[%+v]", router.ErrLimitCurrency, err)
+		}
+	}
+}
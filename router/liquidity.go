@@ -0,0 +1,192 @@
+package router
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var (
+	ErrInvalidSlippage = errors.New("invalid slippage tolerance")
+)
+
+// LiquidityOptions for producing the arguments of liquidity related router calls.
+type LiquidityOptions struct {
+	AllowedSlippage *core.Percent  // How much the amounts are allowed to move unfavorably from the quoted amounts.
+	Recipient       common.Address // The account that sends the calls and receives intermediate tokens and the output.
+	Deadline        *big.Int       // When the transactions expire, in epoch seconds. Takes precedence over TTL.
+
+	TTL          time.Duration // Optional. Lifetime of the transactions from DeadlineBase or from the clock time. Defaults to DefaultTTL.
+	DeadlineBase *big.Int      // Optional. Block timestamp, in epoch seconds, the deadline is relative to and validated against.
+	Clock        Clock         // Optional. Tells the time when DeadlineBase is not set. Defaults to SystemClock.
+	MaxTTL       time.Duration // Optional. The furthest the deadline may be in the future. Defaults to DefaultMaxTTL.
+	Dialect      *Dialect      // Optional. The method names of the router fork. Defaults to UniswapV2.
+}
+
+func (o LiquidityOptions) deadline() (*big.Int, error) {
+	return resolveDeadline(deadlineOptions{
+		Deadline:     o.Deadline,
+		TTL:          o.TTL,
+		DeadlineBase: o.DeadlineBase,
+		Clock:        o.Clock,
+		MaxTTL:       o.MaxTTL,
+	})
+}
+
+// slippageOrZero returns the slippage tolerance, or zero if it is not set
+func slippageOrZero(slippage *core.Percent) (*core.Percent, error) {
+	if slippage == nil {
+		return core.NewPercent(big.NewInt(0), big.NewInt(1)), nil
+	}
+	if slippage.LessThan(entities.ZeroFraction) {
+		return nil, ErrInvalidSlippage
+	}
+	return slippage, nil
+}
+
+// minBig returns the smaller of a and b
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
+
+// minimumAmount returns the amount reduced by the slippage tolerance, i.e. amount / (1 + slippage)
+func minimumAmount(amount *big.Int, slippage *core.Percent) *big.Int {
+	return core.NewFraction(entities.One, entities.One).
+		Add(slippage.Fraction).
+		Invert().
+		Multiply(core.NewFraction(amount, entities.One)).Quotient()
+}
+
+// ZapInCallParameters produces the router calls of a single sided deposit: the swap of a part of the input
+// and the addLiquidity of the remainder with the swap output.
+// The swap output is received by the recipient, who must send both calls in order.
+// Only the minimum swap output is offered to addLiquidity, so any extra swap output or unused input dust
+// stays with the recipient, and the calls mint less than zap.Liquidity by up to the slippage tolerance.
+// The slippage tolerance applies once: the swap output and both deposited amounts are at least their quoted
+// amounts reduced by it.
+func ZapInCallParameters(zap *entities.ZapInAmounts, options LiquidityOptions) ([]*MethodParameters, error) {
+	slippage, err := slippageOrZero(options.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	to := options.Recipient
+	deadline, err := options.deadline()
+	if err != nil {
+		return nil, err
+	}
+	etherIn := zap.AmountA.Currency.IsNative()
+	tokenA, tokenB := entities.WrappedOf(zap.AmountA.Currency), entities.WrappedOf(zap.AmountB.Currency)
+	path := []common.Address{tokenA.Address, tokenB.Address}
+
+	swapAmount := zap.SwapAmount.Quotient()
+	swapOutputMin := minimumAmount(zap.SwapOutput.Quotient(), slippage)
+	amountA := zap.AmountA.Quotient()
+	// the amount of the input deposited with the quoted swap output, reduced by the slippage tolerance
+	quotedA, err := zap.NextPair.Quote(zap.SwapOutput)
+	if err != nil {
+		return nil, err
+	}
+	amountAMin := minimumAmount(minBig(quotedA.Quotient(), amountA), slippage)
+	// not above what the router takes for the minimum swap output if the pair is as expected after the swap,
+	// which may round lower
+	takenA, err := zap.NextPair.Quote(core.FromRawAmount(tokenB, swapOutputMin))
+	if err != nil {
+		return nil, err
+	}
+	amountAMin = minBig(amountAMin, minBig(takenA.Quotient(), amountA))
+	amountBMin := swapOutputMin
+
+	var swap, add *MethodParameters
+	if etherIn {
+		swap = &MethodParameters{
+			MethodName: "swapExactETHForTokens",
+			// (uint amountOutMin, address[] calldata path, address to, uint deadline)
+			Args:  []interface{}{swapOutputMin, path, to, deadline},
+			Value: swapAmount,
+		}
+		add = &MethodParameters{
+			MethodName: "addLiquidityETH",
+			// (address token, uint amountTokenDesired, uint amountTokenMin, uint amountETHMin, address to, uint deadline)
+			Args:  []interface{}{tokenB.Address, swapOutputMin, amountBMin, amountAMin, to, deadline},
+			Value: amountA,
+		}
+	} else {
+		swap = &MethodParameters{
+			MethodName: "swapExactTokensForTokens",
+			// (uint amountIn, uint amountOutMin, address[] calldata path, address to, uint deadline)
+			Args:  []interface{}{swapAmount, swapOutputMin, path, to, deadline},
+			Value: big.NewInt(0),
+		}
+		add = &MethodParameters{
+			MethodName: "addLiquidity",
+			// (address tokenA, address tokenB, uint amountADesired, uint amountBDesired, uint amountAMin, uint amountBMin, address to, uint deadline)
+			Args:  []interface{}{tokenA.Address, tokenB.Address, amountA, swapOutputMin, amountAMin, amountBMin, to, deadline},
+			Value: big.NewInt(0),
+		}
+	}
+	return options.Dialect.translate(swap, add), nil
+}
+
+// ZapOutCallParameters produces the router calls of a withdrawal into a single currency: the removeLiquidity
+// of the liquidity tokens and the swap of the other token into the output currency.
+// The removed tokens are received by the recipient, who must send both calls in order. Only the minimum
+// amount of the other token is swapped, so any extra amount stays with the recipient.
+// The calls receive at least zap.MinimumAmountOut of the slippage tolerance, see ZapOutAmounts.Minimums.
+func ZapOutCallParameters(zap *entities.ZapOutAmounts, options LiquidityOptions) ([]*MethodParameters, error) {
+	slippage, err := slippageOrZero(options.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	to := options.Recipient
+	deadline, err := options.deadline()
+	if err != nil {
+		return nil, err
+	}
+	etherOut := zap.OutputAmount.Currency.IsNative()
+	tokenA, tokenB := entities.WrappedOf(zap.AmountA.Currency), entities.WrappedOf(zap.AmountB.Currency)
+	path := []common.Address{tokenB.Address, tokenA.Address}
+
+	liquidity := zap.Liquidity.Quotient()
+	minimums, err := zap.Minimums(slippage)
+	if err != nil {
+		return nil, err
+	}
+	amountAMin, amountBMin, swapOutputMin := minimums.AmountA.Quotient(), minimums.AmountB.Quotient(), minimums.SwapOutput.Quotient()
+
+	var remove, swap *MethodParameters
+	if etherOut {
+		remove = &MethodParameters{
+			MethodName: "removeLiquidityETH",
+			// (address token, uint liquidity, uint amountTokenMin, uint amountETHMin, address to, uint deadline)
+			Args:  []interface{}{tokenB.Address, liquidity, amountBMin, amountAMin, to, deadline},
+			Value: big.NewInt(0),
+		}
+		swap = &MethodParameters{
+			MethodName: "swapExactTokensForETH",
+			// (uint amountIn, uint amountOutMin, address[] calldata path, address to, uint deadline)
+			Args:  []interface{}{amountBMin, swapOutputMin, path, to, deadline},
+			Value: big.NewInt(0),
+		}
+	} else {
+		remove = &MethodParameters{
+			MethodName: "removeLiquidity",
+			// (address tokenA, address tokenB, uint liquidity, uint amountAMin, uint amountBMin, address to, uint deadline)
+			Args:  []interface{}{tokenA.Address, tokenB.Address, liquidity, amountAMin, amountBMin, to, deadline},
+			Value: big.NewInt(0),
+		}
+		swap = &MethodParameters{
+			MethodName: "swapExactTokensForTokens",
+			// (uint amountIn, uint amountOutMin, address[] calldata path, address to, uint deadline)
+			Args:  []interface{}{amountBMin, swapOutputMin, path, to, deadline},
+			Value: big.NewInt(0),
+		}
+	}
+	return options.Dialect.translate(remove, swap), nil
+}
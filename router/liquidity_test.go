@@ -0,0 +1,154 @@
+package router_test
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestZapInCallParameters(t *testing.T) {
+	testNumber = 0
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(token0, million), core.FromRawAmount(token1, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	zap, err := pair.GetZapInAmounts(totalSupply, core.FromRawAmount(token0, big.NewInt(10000)), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls, err := router.ZapInCallParameters(zap, router.LiquidityOptions{
+		AllowedSlippage: slippage,
+		Recipient:       recipient,
+		Deadline:        deadline,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, 2, len(calls))
+	check(t, "swapExactTokensForTokens", calls[0].MethodName)
+	check(t, big.NewInt(4995), calls[0].Args[0])
+	check(t, big.NewInt(4905), calls[0].Args[1])
+	check(t, []common.Address{token0.Address, token1.Address}, calls[0].Args[2])
+	check(t, recipient, calls[0].Args[3])
+	check(t, "addLiquidity", calls[1].MethodName)
+	check(t, token0.Address, calls[1].Args[0])
+	check(t, token1.Address, calls[1].Args[1])
+	check(t, big.NewInt(5005), calls[1].Args[2])
+	check(t, big.NewInt(4905), calls[1].Args[3])
+	check(t, big.NewInt(4954), calls[1].Args[4])
+	check(t, big.NewInt(4905), calls[1].Args[5])
+	check(t, big.NewInt(0), calls[1].Value)
+	for _, call := range calls {
+		if _, err := call.Pack(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZapInEtherCallParameters(t *testing.T) {
+	testNumber = 0
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(core.WETH9[1], million), core.FromRawAmount(token0, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	zap, err := pair.GetZapInAmounts(totalSupply, core.FromRawAmount(ether, big.NewInt(10000)), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls, err := router.ZapInCallParameters(zap, router.LiquidityOptions{
+		Recipient: recipient,
+		Deadline:  deadline,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "swapExactETHForTokens", calls[0].MethodName)
+	check(t, big.NewInt(4995), calls[0].Value)
+	check(t, []common.Address{core.WETH9[1].Address, token0.Address}, calls[0].Args[1])
+	check(t, "addLiquidityETH", calls[1].MethodName)
+	check(t, token0.Address, calls[1].Args[0])
+	check(t, big.NewInt(5005), calls[1].Value)
+	for _, call := range calls {
+		if _, err := call.Pack(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZapOutCallParameters(t *testing.T) {
+	testNumber = 0
+	million := big.NewInt(1000000)
+	pair, err := entities.NewPair(core.FromRawAmount(core.WETH9[1], million), core.FromRawAmount(token0, million), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalSupply := core.FromRawAmount(pair.LiquidityToken, million)
+	liquidity := core.FromRawAmount(pair.LiquidityToken, big.NewInt(10000))
+
+	{
+		zap, err := pair.GetZapOutAmounts(token0, totalSupply, liquidity, false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls, err := router.ZapOutCallParameters(zap, router.LiquidityOptions{
+			AllowedSlippage: slippage,
+			Recipient:       recipient,
+			Deadline:        deadline,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, "removeLiquidity", calls[0].MethodName)
+		check(t, token0.Address, calls[0].Args[0])
+		check(t, core.WETH9[1].Address, calls[0].Args[1])
+		check(t, big.NewInt(10000), calls[0].Args[2])
+		check(t, big.NewInt(9900), calls[0].Args[3])
+		check(t, big.NewInt(9900), calls[0].Args[4])
+		check(t, "swapExactTokensForTokens", calls[1].MethodName)
+		check(t, big.NewInt(9900), calls[1].Args[0])
+		check(t, big.NewInt(9772), calls[1].Args[1])
+		check(t, []common.Address{core.WETH9[1].Address, token0.Address}, calls[1].Args[2])
+		// the calls enforce the minimum output of the zap
+		minimum, err := zap.MinimumAmountOut(slippage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, minimum.Quotient(), new(big.Int).Add(calls[0].Args[3].(*big.Int), calls[1].Args[1].(*big.Int)))
+		for _, call := range calls {
+			if _, err := call.Pack(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	{
+		zap, err := pair.GetZapOutAmounts(ether, totalSupply, liquidity, false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls, err := router.ZapOutCallParameters(zap, router.LiquidityOptions{
+			Recipient: recipient,
+			Deadline:  deadline,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, "removeLiquidityETH", calls[0].MethodName)
+		check(t, token0.Address, calls[0].Args[0])
+		check(t, "swapExactTokensForETH", calls[1].MethodName)
+		check(t, []common.Address{token0.Address, core.WETH9[1].Address}, calls[1].Args[2])
+		for _, call := range calls {
+			if _, err := call.Pack(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
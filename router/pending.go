@@ -0,0 +1,339 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+// Router02 revert reasons
+var (
+	ErrExpired                  = errors.New("UniswapV2Router: EXPIRED")
+	ErrInsufficientOutputAmount = errors.New("UniswapV2Router: INSUFFICIENT_OUTPUT_AMOUNT")
+	ErrExcessiveInputAmount     = errors.New("UniswapV2Router: EXCESSIVE_INPUT_AMOUNT")
+	ErrInvalidPath              = errors.New("UniswapV2Router: INVALID_PATH")
+	ErrInsufficientLiquidity    = errors.New("UniswapV2Library: INSUFFICIENT_LIQUIDITY")
+	ErrInsufficientInputAmount  = errors.New("UniswapV2Library: INSUFFICIENT_INPUT_AMOUNT")
+	ErrZeroOutputAmount         = errors.New("UniswapV2Library: INSUFFICIENT_OUTPUT_AMOUNT")
+	ErrPairOutputAmount         = errors.New("UniswapV2: INSUFFICIENT_OUTPUT_AMOUNT")
+	ErrOverflow                 = errors.New("UniswapV2: OVERFLOW")
+	ErrNotPayable               = errors.New("method is not payable")
+)
+
+var (
+	ErrUnknownMethod = errors.New("unknown router method")
+	ErrInvalidArgs   = errors.New("invalid router method arguments")
+	ErrUnknownPair   = errors.New("pair is not in the pair set")
+	ErrRepeatedPair  = errors.New("path crosses a pair more than once")
+)
+
+// UnpackMethodParameters decodes the calldata of a Router02 call sent with value wei
+func UnpackMethodParameters(data []byte, value *big.Int) (*MethodParameters, error) {
+	return (*Dialect)(nil).Unpack(data, value)
+}
+
+// Unpack decodes the calldata of a call of a router of the dialect sent with value wei
+func (d *Dialect) Unpack(data []byte, value *big.Int) (*MethodParameters, error) {
+	if len(data) < 4 {
+		return nil, ErrUnknownMethod
+	}
+	routerABI, err := abi.JSON(strings.NewReader(d.ABI()))
+	if err != nil {
+		return nil, err
+	}
+	method, err := routerABI.MethodById(data[:4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %x", ErrUnknownMethod, data[:4])
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArgs, err)
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return &MethodParameters{MethodName: method.Name, Args: args, Value: value, Dialect: d}, nil
+}
+
+// PendingSwap is the outcome of a swap call applied to a pair set
+type PendingSwap struct {
+	MethodName string
+	ExactIn    bool // Whether the input amount is exact, the output amount otherwise
+	EtherIn    bool
+	EtherOut   bool
+	Path       []common.Address
+	To         common.Address
+	Value      *big.Int         // The wei sent with the call. The router refunds what it does not spend.
+	Amounts    []*big.Int       // The amounts of each step of the path, nil if the swap reverts
+	Pairs      []*entities.Pair // The pair set after the swap, the given pair set if it reverts
+	Revert     error            // The reason the swap reverts, nil if it lands
+}
+
+// Reverted tells whether the swap would revert
+func (s *PendingSwap) Reverted() bool {
+	return s.Revert != nil
+}
+
+// AmountIn returns the amount taken from the sender, or nil if the swap reverts
+func (s *PendingSwap) AmountIn() *big.Int {
+	if s.Amounts == nil {
+		return nil
+	}
+	return s.Amounts[0]
+}
+
+// AmountOut returns the amount sent to the recipient, or nil if the swap reverts
+func (s *PendingSwap) AmountOut() *big.Int {
+	if s.Amounts == nil {
+		return nil
+	}
+	return s.Amounts[len(s.Amounts)-1]
+}
+
+// swapArgs are the decoded arguments of a swap method
+type swapArgs struct {
+	amount   *big.Int // The exact input or output
+	limit    *big.Int // The minimum output or the maximum input
+	deadline *big.Int
+}
+
+// unpackArgs assigns the method arguments to the targets, which are **big.Int, *[]common.Address or *common.Address
+func unpackArgs(args []interface{}, targets ...interface{}) error {
+	if len(args) != len(targets) {
+		return ErrInvalidArgs
+	}
+	for i, target := range targets {
+		var ok bool
+		switch t := target.(type) {
+		case **big.Int:
+			*t, ok = args[i].(*big.Int)
+			ok = ok && *t != nil
+		case *[]common.Address:
+			*t, ok = args[i].([]common.Address)
+		case *common.Address:
+			*t, ok = args[i].(common.Address)
+		}
+		if !ok {
+			return ErrInvalidArgs
+		}
+	}
+	return nil
+}
+
+func decodeSwap(params *MethodParameters) (*PendingSwap, *swapArgs, error) {
+	s := &PendingSwap{MethodName: params.MethodName, Value: params.Value}
+	if s.Value == nil {
+		s.Value = big.NewInt(0)
+	}
+	a := &swapArgs{}
+	var err error
+	switch params.Dialect.Canonical(params.MethodName) {
+	case "swapExactTokensForTokens", "swapExactTokensForTokensSupportingFeeOnTransferTokens":
+		s.ExactIn = true
+		err = unpackArgs(params.Args, &a.amount, &a.limit, &s.Path, &s.To, &a.deadline)
+	case "swapTokensForExactTokens":
+		err = unpackArgs(params.Args, &a.amount, &a.limit, &s.Path, &s.To, &a.deadline)
+	case "swapExactETHForTokens", "swapExactETHForTokensSupportingFeeOnTransferTokens":
+		s.ExactIn, s.EtherIn, a.amount = true, true, s.Value
+		err = unpackArgs(params.Args, &a.limit, &s.Path, &s.To, &a.deadline)
+	case "swapETHForExactTokens":
+		s.EtherIn, a.limit = true, s.Value
+		err = unpackArgs(params.Args, &a.amount, &s.Path, &s.To, &a.deadline)
+	case "swapExactTokensForETH", "swapExactTokensForETHSupportingFeeOnTransferTokens":
+		s.ExactIn, s.EtherOut = true, true
+		err = unpackArgs(params.Args, &a.amount, &a.limit, &s.Path, &s.To, &a.deadline)
+	case "swapTokensForExactETH":
+		s.EtherOut = true
+		err = unpackArgs(params.Args, &a.amount, &a.limit, &s.Path, &s.To, &a.deadline)
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownMethod, params.MethodName)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, a, nil
+}
+
+// maxReserve is the largest balance a pair can hold, reserves are stored as uint112
+var maxReserve = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
+
+// PairKey identifies the pair of two tokens, in either order
+type PairKey [2]common.Address
+
+// PairKeyOf returns the key of the pair of the tokens, the tokens sorted like the pair sorts them
+func PairKeyOf(tokenA, tokenB common.Address) PairKey {
+	if bytes.Compare(tokenA.Bytes(), tokenB.Bytes()) > 0 {
+		tokenA, tokenB = tokenB, tokenA
+	}
+	return PairKey{tokenA, tokenB}
+}
+
+// ApplySwap predicts the pair set after a pending Router02 swap lands in a block of the timestamp, e.g. a swap seen
+// in the mempool. The swap reverts like Router02 on an expired deadline, an invalid path, insufficient liquidity,
+// a zero amount, an input that takes a reserve above uint112, or an output below amountOutMin or an input above
+// amountInMax. Tokens are assumed to take no transfer fee.
+// Returns an error if the call is not a swap, a pair of the path is not in the pair set, or the path crosses a pair
+// more than once, which Router02 quotes on the reserves before the swap.
+// @param params the decoded call, e.g. by UnpackMethodParameters
+// @param pairs the current pair set
+// @param weth the wrapped native token of the router
+// @param timestamp the timestamp of the block, in epoch seconds
+func ApplySwap(params *MethodParameters, pairs []*entities.Pair, weth common.Address, timestamp uint64) (*PendingSwap, error) {
+	s, a, err := decodeSwap(params)
+	if err != nil {
+		return nil, err
+	}
+	s.Pairs = pairs
+	switch {
+	case !s.EtherIn && s.Value.Sign() != 0:
+		s.Revert = fmt.Errorf("%w: %s", ErrNotPayable, s.MethodName)
+	case a.deadline.Cmp(new(big.Int).SetUint64(timestamp)) < 0:
+		s.Revert = ErrExpired
+	case len(s.Path) < 2 ||
+		(s.EtherIn && s.Path[0] != weth) ||
+		(s.EtherOut && s.Path[len(s.Path)-1] != weth):
+		s.Revert = ErrInvalidPath
+	}
+	if s.Revert != nil {
+		return s, nil
+	}
+
+	byKey := make(map[PairKey]*entities.Pair, len(pairs))
+	for _, pair := range pairs {
+		byKey[PairKeyOf(pair.Token0().Address, pair.Token1().Address)] = pair
+	}
+	amounts, nextPairs, err := s.amounts(a.amount, byKey)
+	if errors.Is(err, entities.ErrInsufficientReserves) {
+		s.Revert = ErrInsufficientLiquidity
+		return s, nil
+	}
+	if errors.Is(err, ErrInsufficientInputAmount) || errors.Is(err, ErrZeroOutputAmount) || errors.Is(err, ErrOverflow) {
+		s.Revert = err
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.ExactIn && amounts[len(amounts)-1].Cmp(a.limit) < 0 {
+		s.Revert = ErrInsufficientOutputAmount
+		return s, nil
+	}
+	if s.ExactIn && amounts[len(amounts)-1].Sign() == 0 {
+		// the pair reverts transferring nothing out
+		s.Revert = ErrPairOutputAmount
+		return s, nil
+	}
+	if !s.ExactIn && amounts[0].Cmp(a.limit) > 0 {
+		s.Revert = ErrExcessiveInputAmount
+		return s, nil
+	}
+
+	for _, pair := range nextPairs {
+		byKey[PairKeyOf(pair.Token0().Address, pair.Token1().Address)] = pair
+	}
+	s.Amounts = amounts
+	s.Pairs = make([]*entities.Pair, len(pairs))
+	for i, pair := range pairs {
+		s.Pairs[i] = byKey[PairKeyOf(pair.Token0().Address, pair.Token1().Address)]
+	}
+	return s, nil
+}
+
+// amounts returns the amounts along the path, like getAmountsOut or getAmountsIn, and the pairs after the swap
+func (s *PendingSwap) amounts(amount *big.Int, pairs map[PairKey]*entities.Pair) ([]*big.Int, []*entities.Pair, error) {
+	hops := len(s.Path) - 1
+	amounts := make([]*big.Int, len(s.Path))
+	nextPairs := make([]*entities.Pair, hops)
+	crossed := make(map[PairKey]bool, hops)
+	for i := 0; i < hops; i++ {
+		key := PairKeyOf(s.Path[i], s.Path[i+1])
+		if crossed[key] {
+			return nil, nil, fmt.Errorf("%w: %s %s", ErrRepeatedPair, s.Path[i].Hex(), s.Path[i+1].Hex())
+		}
+		crossed[key] = true
+	}
+	if s.ExactIn {
+		amounts[0] = amount
+	} else {
+		amounts[hops] = amount
+	}
+	for h := 0; h < hops; h++ {
+		i := h
+		if !s.ExactIn {
+			i = hops - 1 - h
+		}
+		pair := pairs[PairKeyOf(s.Path[i], s.Path[i+1])]
+		if pair == nil {
+			return nil, nil, fmt.Errorf("%w: %s %s", ErrUnknownPair, s.Path[i].Hex(), s.Path[i+1].Hex())
+		}
+		var (
+			result *core.CurrencyAmount
+			err    error
+		)
+		if s.ExactIn {
+			if amounts[i].Sign() <= 0 {
+				return nil, nil, ErrInsufficientInputAmount
+			}
+			if !fitsReserve(pair, s.Path[i], amounts[i]) {
+				return nil, nil, ErrOverflow
+			}
+			result, nextPairs[i], err = pair.GetOutputAmount(core.FromRawAmount(tokenOf(pair, s.Path[i]), amounts[i]))
+			if errors.Is(err, entities.ErrInsufficientInputAmount) {
+				// getAmountOut quotes zero, the next hop or the output check reverts
+				result, nextPairs[i], err = core.FromRawAmount(tokenOf(pair, s.Path[i+1]), big.NewInt(0)), pair, nil
+			}
+			if err == nil {
+				amounts[i+1] = result.Quotient()
+			}
+		} else {
+			if amounts[i+1].Sign() <= 0 {
+				return nil, nil, ErrZeroOutputAmount
+			}
+			// the input is quoted first, the pair after the swap could not hold a reserve beyond 256 bits
+			var amountIn uint256.Int
+			amountOut, overflow := uint256.FromBig(amounts[i+1])
+			if overflow {
+				return nil, nil, entities.ErrInsufficientReserves
+			}
+			err = pair.QuoteInput(&amountIn, tokenOf(pair, s.Path[i+1]), amountOut)
+			if errors.Is(err, entities.ErrAmountOverflow) || (err == nil && !fitsReserve(pair, s.Path[i], amountIn.ToBig())) {
+				return nil, nil, ErrOverflow
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			result, nextPairs[i], err = pair.GetInputAmount(core.FromRawAmount(tokenOf(pair, s.Path[i+1]), amounts[i+1]))
+			if err == nil {
+				amounts[i] = result.Quotient()
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return amounts, nextPairs, nil
+}
+
+func tokenOf(pair *entities.Pair, address common.Address) *core.Token {
+	if pair.Token0().Address == address {
+		return pair.Token0()
+	}
+	return pair.Token1()
+}
+
+// fitsReserve tells whether the reserve of the token plus the amount fits the uint112 reserves of the pair
+func fitsReserve(pair *entities.Pair, token common.Address, amount *big.Int) bool {
+	reserve, err := pair.ReserveOf(tokenOf(pair, token))
+	if err != nil {
+		return false
+	}
+	return new(big.Int).Add(reserve.Quotient(), amount).Cmp(maxReserve) <= 0
+}
@@ -0,0 +1,128 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestUnpackMethodParameters(t *testing.T) {
+	testNumber = 0
+	route, err := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1}, ether, token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.SwapCallParameters(trade, router.TradeOptions{AllowedSlippage: slippage, Recipient: recipient, Deadline: deadline})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := params.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := router.UnpackMethodParameters(data, params.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, params.MethodName, decoded.MethodName)
+	check(t, fmt.Sprint(params.Args), fmt.Sprint(decoded.Args))
+	check(t, params.Value, decoded.Value)
+
+	_, err = router.UnpackMethodParameters([]byte{1, 2, 3, 4}, nil)
+	check(t, true, errors.Is(err, router.ErrUnknownMethod))
+	_, err = router.UnpackMethodParameters(data[:40], nil)
+	check(t, true, errors.Is(err, router.ErrInvalidArgs))
+}
+
+func TestApplySwap(t *testing.T) {
+	testNumber = 0
+	weth := core.WETH9[1].Address
+	pairs := []*entities.Pair{pair_0_1, pair_weth_0}
+	path := []common.Address{token0.Address, token1.Address}
+	timestamp := deadline.Uint64()
+	swap := func(method string, value int64, args ...interface{}) *router.MethodParameters {
+		return &router.MethodParameters{MethodName: method, Args: args, Value: big.NewInt(value)}
+	}
+
+	// 100 t0 for 90 t1 lands and moves the pair
+	s, err := router.ApplySwap(swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(90), path, recipient, deadline), pairs, weth, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, false, s.Reverted())
+	check(t, "[100 90]", fmt.Sprint(s.Amounts))
+	check(t, "1100 910", fmt.Sprint(s.Pairs[0].Reserve0().Quotient(), s.Pairs[0].Reserve1().Quotient()))
+	check(t, pair_weth_0, s.Pairs[1])
+	check(t, "1000 1000", fmt.Sprint(pair_0_1.Reserve0().Quotient(), pair_0_1.Reserve1().Quotient()))
+
+	// exact output through two pairs, ETH in with a refund
+	s, err = router.ApplySwap(swap("swapETHForExactTokens", 200, big.NewInt(90), []common.Address{weth, token0.Address, token1.Address}, recipient, deadline), pairs, weth, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, false, s.Reverted())
+	check(t, "[112 100 90]", fmt.Sprint(s.Amounts))
+	check(t, true, s.EtherIn)
+
+	tests := []struct {
+		Name   string
+		Params *router.MethodParameters
+		Revert error
+	}{
+		{"output below amountOutMin", swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(91), path, recipient, deadline), router.ErrInsufficientOutputAmount},
+		{"input above amountInMax", swap("swapTokensForExactTokens", 0, big.NewInt(90), big.NewInt(99), path, recipient, deadline), router.ErrExcessiveInputAmount},
+		{"value below input", swap("swapETHForExactTokens", 10, big.NewInt(90), []common.Address{weth, token0.Address}, recipient, deadline), router.ErrExcessiveInputAmount},
+		{"expired", swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(0), path, recipient, big.NewInt(int64(timestamp-1))), router.ErrExpired},
+		{"ETH path", swap("swapExactETHForTokens", 100, big.NewInt(0), path, recipient, deadline), router.ErrInvalidPath},
+		{"short path", swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(0), path[:1], recipient, deadline), router.ErrInvalidPath},
+		{"liquidity", swap("swapTokensForExactTokens", 0, big.NewInt(1000), big.NewInt(1e6), path, recipient, deadline), router.ErrInsufficientLiquidity},
+		{"not payable", swap("swapExactTokensForTokens", 1, big.NewInt(100), big.NewInt(0), path, recipient, deadline), router.ErrNotPayable},
+		{"zero input", swap("swapExactTokensForTokens", 0, big.NewInt(0), big.NewInt(0), path, recipient, deadline), router.ErrInsufficientInputAmount},
+		{"zero output", swap("swapTokensForExactTokens", 0, big.NewInt(0), big.NewInt(100), path, recipient, deadline), router.ErrZeroOutputAmount},
+		{"output rounds to zero", swap("swapExactTokensForTokens", 0, big.NewInt(1), big.NewInt(0), path, recipient, deadline), router.ErrPairOutputAmount},
+		{"input above uint112", swap("swapExactTokensForTokens", 0, core.MaxUint256, big.NewInt(0), path, recipient, deadline), router.ErrOverflow},
+		{"hop output rounds to zero", swap("swapExactETHForTokens", 1, big.NewInt(0), []common.Address{weth, token0.Address, token1.Address}, recipient, deadline), router.ErrInsufficientInputAmount},
+	}
+	for _, test := range tests {
+		s, err := router.ApplySwap(test.Params, pairs, weth, timestamp)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		if !errors.Is(s.Revert, test.Revert) {
+			t.Errorf("%s: expect[%v], but got[%v]", test.Name, test.Revert, s.Revert)
+		}
+		if s.Amounts != nil || s.Pairs[0] != pair_0_1 {
+			t.Errorf("%s: expect[unchanged pairs], but got[%v]", test.Name, s.Pairs)
+		}
+	}
+
+	// the input of 999 t1 takes the t0 reserve above uint112
+	deep, err := entities.NewPair(core.FromRawAmount(token0, new(big.Int).Lsh(big.NewInt(1), 111)), core.FromRawAmount(token1, big.NewInt(1000)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = router.ApplySwap(swap("swapTokensForExactTokens", 0, big.NewInt(999), core.MaxUint256, path, recipient, deadline), []*entities.Pair{deep}, weth, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, router.ErrOverflow, s.Revert)
+
+	_, err = router.ApplySwap(swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(0), []common.Address{token1.Address, weth}, recipient, deadline), pairs, weth, timestamp)
+	check(t, true, errors.Is(err, router.ErrUnknownPair))
+	_, err = router.ApplySwap(swap("swapExactTokensForTokens", 0, big.NewInt(100), big.NewInt(0), []common.Address{token0.Address, token1.Address, token0.Address}, recipient, deadline), pairs, weth, timestamp)
+	check(t, true, errors.Is(err, router.ErrRepeatedPair))
+	_, err = router.ApplySwap(swap("addLiquidity", 0), pairs, weth, timestamp)
+	check(t, true, errors.Is(err, router.ErrUnknownMethod))
+	_, err = router.ApplySwap(swap("swapExactTokensForTokens", 0, big.NewInt(100)), pairs, weth, timestamp)
+	check(t, true, errors.Is(err, router.ErrInvalidArgs))
+}
@@ -0,0 +1,333 @@
+package router
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+// Permit2Address is the address of the Permit2 contract, the same on every chain
+var Permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+// CommandPermit2Permit is PERMIT2_PERMIT(PermitSingle permitSingle, bytes signature)
+const CommandPermit2Permit CommandType = 0x0a
+
+var (
+	ErrPermitAmount     = errors.New("permit amount is missing or out of range")
+	ErrPermitExpiration = errors.New("permit expiration or nonce exceeds uint48")
+	ErrPermitDeadline   = errors.New("permit deadline or nonce is missing or exceeds uint256")
+	ErrSignature        = errors.New("invalid signature")
+	ErrPermitMismatch   = errors.New("permit does not cover the trade input")
+)
+
+// EIP-712 type hashes of Permit2
+var (
+	permit2DomainTypeHash      = crypto.Keccak256Hash([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)"))
+	permitDetailsTypeHash      = crypto.Keccak256Hash([]byte("PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"))
+	permitSingleTypeHash       = crypto.Keccak256Hash([]byte("PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"))
+	tokenPermissionsTypeHash   = crypto.Keccak256Hash([]byte("TokenPermissions(address token,uint256 amount)"))
+	permitTransferFromTypeHash = crypto.Keccak256Hash([]byte("PermitTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline)TokenPermissions(address token,uint256 amount)"))
+	maxUint160                 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	maxUint48                  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 48), big.NewInt(1))
+)
+
+// PermitDetails of an allowance granted through Permit2
+type PermitDetails struct {
+	Token      common.Address // The token to allow
+	Amount     *big.Int       // The allowed amount, at most uint160
+	Expiration *big.Int       // When the allowance expires, in epoch seconds, at most uint48
+	Nonce      *big.Int       // The nonce of the owner, token and spender allowance, at most uint48
+}
+
+// PermitSingle grants the spender an allowance of a token through Permit2. The Universal Router consumes it
+// with the PERMIT2_PERMIT command.
+type PermitSingle struct {
+	Details     PermitDetails
+	Spender     common.Address // The account allowed to spend, e.g. the Universal Router
+	SigDeadline *big.Int       // When the signature expires, in epoch seconds
+}
+
+// SignedPermit is a permit with the signature of the owner
+type SignedPermit struct {
+	Permit    *PermitSingle
+	Signature []byte
+}
+
+// NewTradePermit returns the permit allowing the spender the maximum input of the trade for the allowed slippage
+func NewTradePermit(trade *entities.Trade, slippage *core.Percent, spender common.Address, nonce, expiration, sigDeadline *big.Int) (*PermitSingle, error) {
+	if trade.InputAmount().Currency.IsNative() {
+		return nil, ErrPermitMismatch
+	}
+	slippage, err := slippageOrZero(slippage)
+	if err != nil {
+		return nil, err
+	}
+	amountIn, err := trade.MaximumAmountIn(slippage)
+	if err != nil {
+		return nil, err
+	}
+	permit := &PermitSingle{
+		Details: PermitDetails{
+			Token:      entities.WrappedOf(amountIn.Currency).Address,
+			Amount:     amountIn.Quotient(),
+			Expiration: expiration,
+			Nonce:      nonce,
+		},
+		Spender:     spender,
+		SigDeadline: sigDeadline,
+	}
+	if err := permit.validate(); err != nil {
+		return nil, err
+	}
+	return permit, nil
+}
+
+// TokenPermissions of a one time transfer through Permit2
+type TokenPermissions struct {
+	Token  common.Address
+	Amount *big.Int
+}
+
+// PermitTransferFrom allows the spender a single transfer through Permit2, with an unordered nonce
+type PermitTransferFrom struct {
+	Permitted TokenPermissions
+	Spender   common.Address
+	Nonce     *big.Int
+	Deadline  *big.Int
+}
+
+// HashSigner signs a 32 byte digest, returning the 65 byte [R || S || V] signature with V 27 or 28
+type HashSigner interface {
+	SignHash(hash common.Hash) ([]byte, error)
+}
+
+// KeySigner signs with a private key held in memory
+type KeySigner struct {
+	Key *ecdsa.PrivateKey
+}
+
+func (s KeySigner) SignHash(hash common.Hash) ([]byte, error) {
+	signature, err := crypto.Sign(hash.Bytes(), s.Key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+// Permit2DomainSeparator returns the EIP-712 domain separator of Permit2 on the chain
+func Permit2DomainSeparator(chainID *big.Int) common.Hash {
+	return crypto.Keccak256Hash(
+		permit2DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte("Permit2")),
+		math.U256Bytes(new(big.Int).Set(chainID)),
+		common.LeftPadBytes(Permit2Address.Bytes(), 32),
+	)
+}
+
+// typedDataHash returns the EIP-712 digest of the struct hash in the Permit2 domain
+func typedDataHash(chainID *big.Int, structHash []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte("\x19\x01"), Permit2DomainSeparator(chainID).Bytes(), structHash)
+}
+
+func (d PermitDetails) validate() error {
+	if d.Amount == nil || d.Amount.Sign() < 0 || d.Amount.Cmp(maxUint160) > 0 {
+		return ErrPermitAmount
+	}
+	for _, v := range []*big.Int{d.Expiration, d.Nonce} {
+		if v == nil || v.Sign() < 0 || v.Cmp(maxUint48) > 0 {
+			return ErrPermitExpiration
+		}
+	}
+	return nil
+}
+
+// isUint256 returns whether v is set and fits uint256
+func isUint256(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(math.MaxBig256) <= 0
+}
+
+func (p *PermitSingle) validate() error {
+	if err := p.Details.validate(); err != nil {
+		return err
+	}
+	if !isUint256(p.SigDeadline) {
+		return ErrPermitDeadline
+	}
+	return nil
+}
+
+func (d PermitDetails) hash() []byte {
+	return crypto.Keccak256(
+		permitDetailsTypeHash.Bytes(),
+		common.LeftPadBytes(d.Token.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(d.Amount)),
+		math.U256Bytes(new(big.Int).Set(d.Expiration)),
+		math.U256Bytes(new(big.Int).Set(d.Nonce)),
+	)
+}
+
+// Hash returns the EIP-712 digest of the permit on the chain, the hash to sign
+func (p *PermitSingle) Hash(chainID *big.Int) (common.Hash, error) {
+	if err := p.validate(); err != nil {
+		return common.Hash{}, err
+	}
+	structHash := crypto.Keccak256(
+		permitSingleTypeHash.Bytes(),
+		p.Details.hash(),
+		common.LeftPadBytes(p.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(p.SigDeadline)),
+	)
+	return typedDataHash(chainID, structHash), nil
+}
+
+// Sign returns the signature of the permit on the chain
+func (p *PermitSingle) Sign(chainID *big.Int, signer HashSigner) ([]byte, error) {
+	hash, err := p.Hash(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return signer.SignHash(hash)
+}
+
+func (p *PermitTransferFrom) validate() error {
+	if !isUint256(p.Permitted.Amount) {
+		return ErrPermitAmount
+	}
+	if !isUint256(p.Nonce) || !isUint256(p.Deadline) {
+		return ErrPermitDeadline
+	}
+	return nil
+}
+
+// Hash returns the EIP-712 digest of the transfer permit on the chain, the hash to sign
+func (p *PermitTransferFrom) Hash(chainID *big.Int) (common.Hash, error) {
+	if err := p.validate(); err != nil {
+		return common.Hash{}, err
+	}
+	permitted := crypto.Keccak256(
+		tokenPermissionsTypeHash.Bytes(),
+		common.LeftPadBytes(p.Permitted.Token.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(p.Permitted.Amount)),
+	)
+	structHash := crypto.Keccak256(
+		permitTransferFromTypeHash.Bytes(),
+		permitted,
+		common.LeftPadBytes(p.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(p.Nonce)),
+		math.U256Bytes(new(big.Int).Set(p.Deadline)),
+	)
+	return typedDataHash(chainID, structHash), nil
+}
+
+// Sign returns the signature of the transfer permit on the chain
+func (p *PermitTransferFrom) Sign(chainID *big.Int, signer HashSigner) ([]byte, error) {
+	hash, err := p.Hash(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return signer.SignHash(hash)
+}
+
+// RecoverPermitSigner returns the account that signed the digest
+func RecoverPermitSigner(hash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+		return common.Address{}, ErrSignature
+	}
+	sig := common.CopyBytes(signature)
+	sig[64] -= 27
+	key, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+// permit2PermitArguments is the ABI layout of the PERMIT2_PERMIT input
+func permit2PermitArguments() (abi.Arguments, error) {
+	permitSingle, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "details", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "token", Type: "address"},
+			{Name: "amount", Type: "uint160"},
+			{Name: "expiration", Type: "uint48"},
+			{Name: "nonce", Type: "uint48"},
+		}},
+		{Name: "spender", Type: "address"},
+		{Name: "sigDeadline", Type: "uint256"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return abi.Arguments{{Type: permitSingle}, {Type: signature}}, nil
+}
+
+// permitTuple mirrors the PermitSingle tuple for the ABI encoder
+type permitTuple struct {
+	Details struct {
+		Token      common.Address
+		Amount     *big.Int
+		Expiration *big.Int
+		Nonce      *big.Int
+	}
+	Spender     common.Address
+	SigDeadline *big.Int
+}
+
+// AddPermit appends the PERMIT2_PERMIT command granting the router the signed allowance
+func (p *ExecuteParameters) AddPermit(permit *PermitSingle, signature []byte) error {
+	if err := permit.validate(); err != nil {
+		return err
+	}
+	arguments, err := permit2PermitArguments()
+	if err != nil {
+		return err
+	}
+	var tuple permitTuple
+	tuple.Details.Token = permit.Details.Token
+	tuple.Details.Amount = permit.Details.Amount
+	tuple.Details.Expiration = permit.Details.Expiration
+	tuple.Details.Nonce = permit.Details.Nonce
+	tuple.Spender = permit.Spender
+	tuple.SigDeadline = permit.SigDeadline
+	input, err := arguments.Pack(tuple, signature)
+	if err != nil {
+		return err
+	}
+	p.Commands = append(p.Commands, byte(CommandPermit2Permit))
+	p.Inputs = append(p.Inputs, input)
+	return nil
+}
+
+// UnpackPermit decodes the input of a PERMIT2_PERMIT command
+func UnpackPermit(input []byte) (*PermitSingle, []byte, error) {
+	arguments, err := permit2PermitArguments()
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := arguments.Unpack(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	tuple := *abi.ConvertType(values[0], new(permitTuple)).(*permitTuple)
+	return &PermitSingle{
+		Details: PermitDetails{
+			Token:      tuple.Details.Token,
+			Amount:     tuple.Details.Amount,
+			Expiration: tuple.Details.Expiration,
+			Nonce:      tuple.Details.Nonce,
+		},
+		Spender:     tuple.Spender,
+		SigDeadline: tuple.SigDeadline,
+	}, values[1].([]byte), nil
+}
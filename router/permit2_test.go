@@ -0,0 +1,200 @@
+package router_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+var universalRouterAddress = common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD")
+
+func permit2Domain(chainID int64) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              "Permit2",
+		ChainId:           math.NewHexOrDecimal256(chainID),
+		VerifyingContract: router.Permit2Address.Hex(),
+	}
+}
+
+var permit2DomainType = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+func typedDataHash(t *testing.T, data apitypes.TypedData) common.Hash {
+	domain, err := data.HashStruct("EIP712Domain", data.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := data.HashStruct(data.PrimaryType, data.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domain, message)
+}
+
+func TestPermit2DomainSeparator(t *testing.T) {
+	testNumber = 0
+	// DOMAIN_SEPARATOR() of Permit2 on mainnet
+	check(t, "0x866a5aba21966af95d6c7ab78eb2b2fc913915c28be3b9aa07cc04ff903e3f28", router.Permit2DomainSeparator(big.NewInt(1)).Hex())
+}
+
+func TestPermitSingleHash(t *testing.T) {
+	testNumber = 0
+	permit := &router.PermitSingle{
+		Details: router.PermitDetails{
+			Token:      token0.Address,
+			Amount:     big.NewInt(1000),
+			Expiration: big.NewInt(1700000000),
+			Nonce:      big.NewInt(7),
+		},
+		Spender:     universalRouterAddress,
+		SigDeadline: big.NewInt(1690000000),
+	}
+	hash, err := permit.Hash(big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PERMIT_DETAILS_TYPEHASH and _PERMIT_SINGLE_TYPEHASH of Permit2. This version of apitypes rejects uint160 and
+	// uint48 values, so only the type encoding is compared.
+	types := apitypes.TypedData{Types: apitypes.Types{
+		"PermitSingle": {
+			{Name: "details", Type: "PermitDetails"},
+			{Name: "spender", Type: "address"},
+			{Name: "sigDeadline", Type: "uint256"},
+		},
+		"PermitDetails": {
+			{Name: "token", Type: "address"},
+			{Name: "amount", Type: "uint160"},
+			{Name: "expiration", Type: "uint48"},
+			{Name: "nonce", Type: "uint48"},
+		},
+	}}
+	check(t, "0x65626cad6cb96493bf6f5ebea28756c966f023ab9e8a83a7101849d5573b3678", types.TypeHash("PermitDetails").String())
+	check(t, "0xf3841cd1ff0085026a6327b620b67997ce40f282c88a8e905a7a5626e310f3d0", types.TypeHash("PermitSingle").String())
+	check(t, "0x8094af6d6cda6fb8f7d40918bf1adf156501862928abd33b454609fe44e3f403", hash.Hex())
+
+	// the chain is part of the domain
+	other, _ := permit.Hash(big.NewInt(5))
+	check(t, false, hash == other)
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	signature, err := permit.Sign(big.NewInt(1), router.KeySigner{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, 65, len(signature))
+	signer, err := router.RecoverPermitSigner(hash, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	permit.Details.Amount = new(big.Int).Lsh(big.NewInt(1), 160)
+	_, err = permit.Hash(big.NewInt(1))
+	check(t, router.ErrPermitAmount, err)
+
+	permit.Details.Amount, permit.SigDeadline = big.NewInt(1), nil
+	_, err = permit.Hash(big.NewInt(1))
+	check(t, router.ErrPermitDeadline, err)
+}
+
+func TestPermitTransferFromHash(t *testing.T) {
+	testNumber = 0
+	permit := &router.PermitTransferFrom{
+		Permitted: router.TokenPermissions{Token: token0.Address, Amount: big.NewInt(1000)},
+		Spender:   universalRouterAddress,
+		Nonce:     big.NewInt(42),
+		Deadline:  big.NewInt(1690000000),
+	}
+	expect := typedDataHash(t, apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": permit2DomainType,
+			"PermitTransferFrom": {
+				{Name: "permitted", Type: "TokenPermissions"},
+				{Name: "spender", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+			"TokenPermissions": {
+				{Name: "token", Type: "address"},
+				{Name: "amount", Type: "uint256"},
+			},
+		},
+		PrimaryType: "PermitTransferFrom",
+		Domain:      permit2Domain(1),
+		Message: apitypes.TypedDataMessage{
+			"permitted": map[string]interface{}{
+				"token":  token0.Address.Hex(),
+				"amount": "1000",
+			},
+			"spender":  universalRouterAddress.Hex(),
+			"nonce":    "42",
+			"deadline": "1690000000",
+		},
+	})
+	hash, err := permit.Hash(big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, expect, hash)
+
+	permit.Deadline = nil
+	_, err = permit.Hash(big.NewInt(1))
+	check(t, router.ErrPermitDeadline, err)
+	permit.Deadline, permit.Permitted.Amount = big.NewInt(1690000000), nil
+	_, err = permit.Hash(big.NewInt(1))
+	check(t, router.ErrPermitAmount, err)
+}
+
+func TestUniversalPermit(t *testing.T) {
+	testNumber = 0
+	route, _ := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	trade, err := entities.ExactOut(route, core.FromRawAmount(token1, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	permit, err := router.NewTradePermit(trade, slippage, universalRouterAddress, big.NewInt(0), big.NewInt(1700000000), deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxAmountIn, _ := trade.MaximumAmountIn(slippage)
+	check(t, token0.Address, permit.Details.Token)
+	check(t, maxAmountIn.Quotient(), permit.Details.Amount)
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	signature, _ := permit.Sign(big.NewInt(1), router.KeySigner{Key: key})
+	options := universalOptions()
+	options.Permit = &router.SignedPermit{Permit: permit, Signature: signature}
+	params, err := router.UniversalSwapCallParameters(trade, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x0a09", hexutil.Encode(params.Commands))
+	decoded, decodedSignature, err := router.UnpackPermit(params.Inputs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, fmt.Sprint(permit), fmt.Sprint(decoded))
+	check(t, signature, decodedSignature)
+
+	// the permit must cover the maximum input
+	permit.Details.Amount = big.NewInt(1)
+	_, err = router.UniversalSwapCallParameters(trade, options)
+	check(t, router.ErrPermitMismatch, err)
+
+	route, _ = entities.NewRoute([]*entities.Pair{pair_weth_0}, ether, token0)
+	trade, _ = entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	_, err = router.NewTradePermit(trade, slippage, universalRouterAddress, big.NewInt(0), big.NewInt(1700000000), deadline)
+	check(t, router.ErrPermitMismatch, err)
+}
@@ -22,17 +22,43 @@ var (
 type TradeOptions struct {
 	AllowedSlippage *core.Percent  // How much the execution price is allowed to move unfavorably from the trade execution price.
 	Recipient       common.Address // The account that should receive the output.
-	Deadline        *big.Int       // When the transaction expires, in epoch seconds.
+	Deadline        *big.Int       // When the transaction expires, in epoch seconds. Takes precedence over TTL.
 	FeeOnTransfer   bool           // Whether any of the tokens in the path are fee on transfer tokens, which should be handled with special methods
+	WrappedInput    bool           // Whether a native input is paid in the wrapped token, e.g. WETH rather than ETH
+	WrappedOutput   bool           // Whether a native output is received in the wrapped token, e.g. WETH rather than ETH
+	Dialect         *Dialect       // Optional. The method names of the router fork. Defaults to UniswapV2.
+
+	TTL          time.Duration // Optional. Lifetime of the transaction from DeadlineBase or from the clock time. Defaults to DefaultTTL.
+	DeadlineBase *big.Int      // Optional. Block timestamp, in epoch seconds, the deadline is relative to and validated against.
+	Clock        Clock         // Optional. Tells the time when DeadlineBase is not set. Defaults to SystemClock.
+	MaxTTL       time.Duration // Optional. The furthest the deadline may be in the future. Defaults to DefaultMaxTTL.
+
+	MaxPriceImpact *core.Percent        // Optional. The largest price impact of the trade to build the call for.
+	MaxHops        int                  // Optional. The largest number of pairs the trade may go through.
+	MinimumOutput  *core.CurrencyAmount // Optional. The smallest slippage adjusted output of the trade to build the call for.
 }
 
-// SwapParameters to use in the call to the Uniswap V2 Router to execute a trade.
-type SwapParameters struct {
+// MethodParameters to use in a call to the Uniswap V2 Router.
+type MethodParameters struct {
 	MethodName string        // The method to call on the Uniswap V2 Router.
 	Args       []interface{} // The arguments to pass to the method.
 	Value      *big.Int      // The amount of wei to send.
+	Dialect    *Dialect      // The dialect of MethodName, nil for UniswapV2.
 }
 
+func (o TradeOptions) deadline() (*big.Int, error) {
+	return resolveDeadline(deadlineOptions{
+		Deadline:     o.Deadline,
+		TTL:          o.TTL,
+		DeadlineBase: o.DeadlineBase,
+		Clock:        o.Clock,
+		MaxTTL:       o.MaxTTL,
+	})
+}
+
+// SwapParameters to use in the call to the Uniswap V2 Router to execute a trade.
+type SwapParameters = MethodParameters
+
 // toHex converts a big int to a hex string
 func toHex(i *big.Int) string {
 	if i == nil {
@@ -46,13 +72,27 @@ func toHex(i *big.Int) string {
 	return "0x" + hex
 }
 
+// resolveCurrencies returns entities.ErrUnknownNative if the input or output of the trade is a native currency
+// without a wrapped token
+func resolveCurrencies(trade *entities.Trade) error {
+	for _, currency := range []core.Currency{trade.InputAmount().Currency, trade.OutputAmount().Currency} {
+		if _, err := entities.ResolveWrapped(currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SwapCallParameters produces the on-chain method name to call and the hex encoded parameters to pass as arguments for a given trade.
 func SwapCallParameters(trade *entities.Trade, options TradeOptions) (*SwapParameters, error) {
-	etherIn := trade.InputAmount().Currency.IsNative()
-	etherOut := trade.OutputAmount().Currency.IsNative()
+	etherIn := trade.InputAmount().Currency.IsNative() && !options.WrappedInput
+	etherOut := trade.OutputAmount().Currency.IsNative() && !options.WrappedOutput
 	if etherIn && etherOut {
 		return nil, ErrEtherInOut
 	}
+	if err := resolveCurrencies(trade); err != nil {
+		return nil, err
+	}
 	to := options.Recipient
 	slippage := options.AllowedSlippage
 	if slippage == nil {
@@ -68,13 +108,16 @@ func SwapCallParameters(trade *entities.Trade, options TradeOptions) (*SwapParam
 		return nil, err
 	}
 	amountOut := minAmountOut.Quotient()
+	if err := checkLimits(trade, options, minAmountOut); err != nil {
+		return nil, err
+	}
 	var path []common.Address
 	for _, token := range trade.Route.Path {
 		path = append(path, token.Address)
 	}
-	deadline := options.Deadline
-	if options.Deadline == nil {
-		deadline = big.NewInt(time.Now().Add(5 * time.Minute).Unix())
+	deadline, err := options.deadline()
+	if err != nil {
+		return nil, err
 	}
 
 	var (
@@ -133,9 +176,10 @@ func SwapCallParameters(trade *entities.Trade, options TradeOptions) (*SwapParam
 		value = big.NewInt(0)
 	}
 	return &SwapParameters{
-		MethodName: methodName,
+		MethodName: options.Dialect.MethodName(methodName),
 		Args:       args,
 		Value:      value,
+		Dialect:    options.Dialect,
 	}, nil
 }
 
@@ -146,13 +190,18 @@ func SwapCallParametersPacked(trade *entities.Trade, options TradeOptions) (*big
 	if err != nil {
 		return nil, nil, err
 	}
-	routerABI, err := abi.JSON(strings.NewReader(V2Router02ABI))
+	data, err := params.Pack()
 	if err != nil {
 		return nil, nil, err
 	}
-	data, err := routerABI.Pack(params.MethodName, params.Args...)
+	return params.Value, data, nil
+}
+
+// Pack encodes the method call with the router ABI of its dialect.
+func (p *MethodParameters) Pack() ([]byte, error) {
+	routerABI, err := abi.JSON(strings.NewReader(p.Dialect.ABI()))
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return params.Value, data, nil
+	return routerABI.Pack(p.MethodName, p.Args...)
 }
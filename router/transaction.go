@@ -0,0 +1,253 @@
+package router
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNoChainID       = errors.New("transaction builder has no chain id")
+	ErrNoNonceProvider = errors.New("transaction builder has no nonce provider")
+	ErrNoFeeProvider   = errors.New("transaction builder has no fee provider")
+	ErrInvalidFees     = errors.New("fee cap is below the tip cap")
+	ErrNoFees          = errors.New("fee provider returned no fees")
+)
+
+// NonceProvider returns the next nonce of an account, ethclient.Client is one
+type NonceProvider interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceReserver is a NonceProvider that keeps track of the nonces it hands out, so that consecutive builds
+// do not reuse them before the transactions are broadcast
+type NonceReserver interface {
+	NonceProvider
+	ReserveNonces(ctx context.Context, account common.Address, count uint64) (uint64, error)
+}
+
+// FeeProvider returns the EIP-1559 fees to pay
+type FeeProvider interface {
+	Fees(ctx context.Context) (gasTipCap, gasFeeCap *big.Int, err error)
+}
+
+// GasEstimator returns the gas limit of a router call
+type GasEstimator interface {
+	GasLimit(params *MethodParameters) (uint64, error)
+}
+
+// NonceCounter is an offline NonceProvider that hands out increasing nonces per account
+type NonceCounter struct {
+	mu     sync.Mutex
+	nonces map[common.Address]uint64
+}
+
+// NewNonceCounter creates a NonceCounter, accounts start at their nonce in start or at zero
+func NewNonceCounter(start map[common.Address]uint64) *NonceCounter {
+	nonces := make(map[common.Address]uint64, len(start))
+	for account, nonce := range start {
+		nonces[account] = nonce
+	}
+	return &NonceCounter{nonces: nonces}
+}
+
+// PendingNonceAt returns the next nonce of the account
+func (c *NonceCounter) PendingNonceAt(_ context.Context, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonces[account], nil
+}
+
+// ReserveNonces returns the first of count consecutive nonces of the account and skips past them
+func (c *NonceCounter) ReserveNonces(_ context.Context, account common.Address, count uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nonce := c.nonces[account]
+	c.nonces[account] = nonce + count
+	return nonce, nil
+}
+
+// StaticFees is a FeeProvider of fixed fees
+type StaticFees struct {
+	GasTipCap *big.Int // Max priority fee per gas
+	GasFeeCap *big.Int // Max fee per gas
+}
+
+// Fees returns the fixed fees
+func (f StaticFees) Fees(context.Context) (*big.Int, *big.Int, error) {
+	return f.GasTipCap, f.GasFeeCap, nil
+}
+
+// GasTable estimates gas limits from a fixed cost per method and a cost per hop of the swap path
+type GasTable struct {
+	Base    map[string]uint64 // Cost of a method, excluding hops
+	PerHop  uint64            // Cost of every pair a swap goes through
+	Default uint64            // Cost of a method missing from Base
+}
+
+// DefaultGasTable has generous limits for Router02 methods on mainnet
+var DefaultGasTable = &GasTable{
+	Base: map[string]uint64{
+		"swapExactTokensForTokens":                              60000,
+		"swapTokensForExactTokens":                              60000,
+		"swapExactETHForTokens":                                 80000,
+		"swapETHForExactTokens":                                 90000,
+		"swapExactTokensForETH":                                 80000,
+		"swapTokensForExactETH":                                 80000,
+		"swapExactTokensForTokensSupportingFeeOnTransferTokens": 90000,
+		"swapExactETHForTokensSupportingFeeOnTransferTokens":    110000,
+		"swapExactTokensForETHSupportingFeeOnTransferTokens":    110000,
+		"addLiquidity":                                          220000,
+		"addLiquidityETH":                                       240000,
+		"removeLiquidity":                                       180000,
+		"removeLiquidityETH":                                    200000,
+	},
+	PerHop:  80000,
+	Default: 300000,
+}
+
+// GasLimit returns the cost of the method plus the cost of the hops of its path argument, if any.
+// Methods of other dialects cost as their Router02 counterpart.
+func (g *GasTable) GasLimit(params *MethodParameters) (uint64, error) {
+	gas, ok := g.Base[params.Dialect.Canonical(params.MethodName)]
+	if !ok {
+		gas = g.Default
+	}
+	if strings.HasPrefix(params.MethodName, "swap") {
+		for _, arg := range params.Args {
+			if path, ok := arg.([]common.Address); ok && len(path) > 1 {
+				gas += g.PerHop * uint64(len(path)-1)
+			}
+		}
+	}
+	return gas, nil
+}
+
+// TransactionBuilder turns router calls into EIP-1559 transactions
+type TransactionBuilder struct {
+	ChainID *big.Int
+	Router  common.Address // Address of the router contract
+	Nonces  NonceProvider
+	Fees    FeeProvider
+	Gas     GasEstimator // Optional. Defaults to DefaultGasTable.
+}
+
+// Build returns the unsigned transaction of the router call sent by from
+func (b *TransactionBuilder) Build(ctx context.Context, from common.Address, params *MethodParameters) (*types.Transaction, error) {
+	txs, err := b.BuildSequence(ctx, from, []*MethodParameters{params})
+	if err != nil {
+		return nil, err
+	}
+	return txs[0], nil
+}
+
+// BuildSequence returns the unsigned transactions of the router calls sent by from, with consecutive nonces
+func (b *TransactionBuilder) BuildSequence(ctx context.Context, from common.Address, methods []*MethodParameters) ([]*types.Transaction, error) {
+	calls := make([]*Call, len(methods))
+	for i, params := range methods {
+		call, err := params.Call(b.Router, b.Gas)
+		if err != nil {
+			return nil, err
+		}
+		calls[i] = call
+	}
+	return b.BuildCalls(ctx, from, calls)
+}
+
+// BuildCalls returns the unsigned transactions of the calls sent by from, with consecutive nonces
+func (b *TransactionBuilder) BuildCalls(ctx context.Context, from common.Address, calls []*Call) ([]*types.Transaction, error) {
+	if b.ChainID == nil {
+		return nil, ErrNoChainID
+	}
+	if b.Nonces == nil {
+		return nil, ErrNoNonceProvider
+	}
+	if b.Fees == nil {
+		return nil, ErrNoFeeProvider
+	}
+
+	tipCap, feeCap, err := b.Fees.Fees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tipCap == nil || feeCap == nil {
+		return nil, ErrNoFees
+	}
+	if tipCap.Sign() < 0 || feeCap.Cmp(tipCap) < 0 {
+		return nil, ErrInvalidFees
+	}
+	var nonce uint64
+	if reserver, ok := b.Nonces.(NonceReserver); ok {
+		nonce, err = reserver.ReserveNonces(ctx, from, uint64(len(calls)))
+	} else {
+		nonce, err = b.Nonces.PendingNonceAt(ctx, from)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*types.Transaction, len(calls))
+	for i, call := range calls {
+		to := call.To
+		value := call.Value
+		if value == nil {
+			value = big.NewInt(0)
+		}
+		txs[i] = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   b.ChainID,
+			Nonce:     nonce + uint64(i),
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Gas:       call.Gas,
+			To:        &to,
+			Value:     value,
+			Data:      call.Data,
+		})
+	}
+	return txs, nil
+}
+
+// BuildSigned returns the router call as a transaction signed with the key
+func (b *TransactionBuilder) BuildSigned(ctx context.Context, key *ecdsa.PrivateKey, params *MethodParameters) (*types.Transaction, error) {
+	txs, err := b.BuildSignedSequence(ctx, key, []*MethodParameters{params})
+	if err != nil {
+		return nil, err
+	}
+	return txs[0], nil
+}
+
+// BuildSignedSequence returns the router calls as transactions signed with the key, with consecutive nonces
+func (b *TransactionBuilder) BuildSignedSequence(ctx context.Context, key *ecdsa.PrivateKey, methods []*MethodParameters) ([]*types.Transaction, error) {
+	txs, err := b.BuildSequence(ctx, crypto.PubkeyToAddress(key.PublicKey), methods)
+	if err != nil {
+		return nil, err
+	}
+	return b.sign(txs, key)
+}
+
+// BuildSignedCalls returns the calls as transactions signed with the key, with consecutive nonces
+func (b *TransactionBuilder) BuildSignedCalls(ctx context.Context, key *ecdsa.PrivateKey, calls []*Call) ([]*types.Transaction, error) {
+	txs, err := b.BuildCalls(ctx, crypto.PubkeyToAddress(key.PublicKey), calls)
+	if err != nil {
+		return nil, err
+	}
+	return b.sign(txs, key)
+}
+
+func (b *TransactionBuilder) sign(txs []*types.Transaction, key *ecdsa.PrivateKey) ([]*types.Transaction, error) {
+	var err error
+	signer := types.NewLondonSigner(b.ChainID)
+	for i := range txs {
+		if txs[i], err = types.SignTx(txs[i], signer, key); err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
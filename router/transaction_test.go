@@ -0,0 +1,133 @@
+package router_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+var routerAddress = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+
+func TestTransactionBuilder(t *testing.T) {
+	testNumber = 0
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	builder := &router.TransactionBuilder{
+		ChainID: big.NewInt(1),
+		Router:  routerAddress,
+		Nonces:  router.NewNonceCounter(map[common.Address]uint64{from: 7}),
+		Fees:    router.StaticFees{GasTipCap: big.NewInt(2000000000), GasFeeCap: big.NewInt(50000000000)},
+	}
+
+	route, err := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1}, ether, token1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.SwapCallParameters(trade, router.TradeOptions{
+		AllowedSlippage: slippage,
+		Recipient:       from,
+		Deadline:        deadline,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signed transaction
+	{
+		tx, err := builder.BuildSigned(context.Background(), key, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, data, _ := router.SwapCallParametersPacked(trade, router.TradeOptions{
+			AllowedSlippage: slippage,
+			Recipient:       from,
+			Deadline:        deadline,
+		})
+		check(t, uint8(types.DynamicFeeTxType), tx.Type())
+		check(t, uint64(7), tx.Nonce())
+		check(t, routerAddress, *tx.To())
+		check(t, big.NewInt(100), tx.Value())
+		check(t, data, tx.Data())
+		// 80000 for the method and 80000 for each of the two hops
+		check(t, uint64(240000), tx.Gas())
+		check(t, big.NewInt(2000000000), tx.GasTipCap())
+		check(t, big.NewInt(50000000000), tx.GasFeeCap())
+		sender, err := types.Sender(types.NewLondonSigner(big.NewInt(1)), tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, from, sender)
+	}
+
+	// consecutive nonces
+	{
+		txs, err := builder.BuildSequence(context.Background(), from, []*router.MethodParameters{params, params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, uint64(8), txs[0].Nonce())
+		check(t, uint64(9), txs[1].Nonce())
+		tx, err := builder.Build(context.Background(), from, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, uint64(10), tx.Nonce())
+		v, r, s := tx.RawSignatureValues()
+		check(t, 0, v.Sign()+r.Sign()+s.Sign())
+	}
+
+	// ordered call lists
+	{
+		approve, _ := router.ApproveCalldata(routerAddress, big.NewInt(100))
+		swap, _ := params.Call(routerAddress, nil)
+		txs, err := builder.BuildSignedCalls(context.Background(), key, []*router.Call{
+			{To: token0.Address, Data: approve, Gas: router.ApproveGas},
+			swap,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, token0.Address, *txs[0].To())
+		check(t, router.ApproveGas, txs[0].Gas())
+		check(t, big.NewInt(0), txs[0].Value())
+		check(t, routerAddress, *txs[1].To())
+		check(t, uint64(12), txs[1].Nonce())
+	}
+
+	// custom gas estimator
+	{
+		custom := *builder
+		custom.Gas = &router.GasTable{PerHop: 1, Default: 10}
+		tx, err := custom.Build(context.Background(), from, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, uint64(12), tx.Gas())
+	}
+
+	// invalid fees
+	{
+		invalid := *builder
+		invalid.Fees = router.StaticFees{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(1)}
+		_, err := invalid.Build(context.Background(), from, params)
+		check(t, router.ErrInvalidFees, err)
+
+		invalid.Fees = router.StaticFees{GasFeeCap: big.NewInt(1)}
+		_, err = invalid.Build(context.Background(), from, params)
+		check(t, router.ErrNoFees, err)
+	}
+}
@@ -0,0 +1,263 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+const UniversalRouterABI = "[ { \"inputs\": [ { \"internalType\": \"bytes\", \"name\": \"commands\", \"type\": \"bytes\" }, { \"internalType\": \"bytes[]\", \"name\": \"inputs\", \"type\": \"bytes[]\" }, { \"internalType\": \"uint256\", \"name\": \"deadline\", \"type\": \"uint256\" } ], \"name\": \"execute\", \"outputs\": [], \"stateMutability\": \"payable\", \"type\": \"function\" } ]"
+
+// CommandType is a Universal Router command, one byte of the commands argument of execute
+type CommandType byte
+
+const (
+	CommandSweep          CommandType = 0x04 // SWEEP(address token, address recipient, uint256 amountMin)
+	CommandPayPortion     CommandType = 0x06 // PAY_PORTION(address token, address recipient, uint256 bips)
+	CommandV2SwapExactIn  CommandType = 0x08 // V2_SWAP_EXACT_IN(address recipient, uint256 amountIn, uint256 amountOutMin, address[] path, bool payerIsUser)
+	CommandV2SwapExactOut CommandType = 0x09 // V2_SWAP_EXACT_OUT(address recipient, uint256 amountOut, uint256 amountInMax, address[] path, bool payerIsUser)
+	CommandWrapETH        CommandType = 0x0b // WRAP_ETH(address recipient, uint256 amountMin)
+	CommandUnwrapWETH     CommandType = 0x0c // UNWRAP_WETH(address recipient, uint256 amountMin)
+
+	// CommandAllowRevert is set on a command whose failure should not revert the whole execution
+	CommandAllowRevert CommandType = 0x80
+)
+
+// The maximum fee portion, in basis points
+const MaxBips = 10000
+
+var (
+	NativeAddress = common.Address{}                                                  // Stands for ether in SWEEP and PAY_PORTION
+	MsgSender     = common.HexToAddress("0x0000000000000000000000000000000000000001") // Stands for the caller of execute
+	AddressThis   = common.HexToAddress("0x0000000000000000000000000000000000000002") // Stands for the Universal Router itself
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown universal router command")
+	ErrInvalidFee     = errors.New("invalid fee")
+)
+
+// FeeOptions takes a portion of the output of a trade
+type FeeOptions struct {
+	Fee       *core.Percent  // The portion of the output to take, at most 100% and in whole basis points
+	Recipient common.Address // The account that receives the fee
+}
+
+// UniversalOptions for producing the execute call of a trade on the Universal Router
+type UniversalOptions struct {
+	TradeOptions
+	Fee    *FeeOptions   // Optional. Takes a portion of the output before sending the rest to the recipient.
+	Permit *SignedPermit // Optional. Grants the router the Permit2 allowance of the input token within the same call.
+}
+
+// ExecuteParameters of a call to execute(commands, inputs, deadline) on the Universal Router
+type ExecuteParameters struct {
+	Commands []byte   // One command per byte
+	Inputs   [][]byte // The ABI encoded input of each command
+	Deadline *big.Int // When the transaction expires, in epoch seconds
+	Value    *big.Int // The amount of wei to send
+}
+
+// commandArguments returns the ABI layout of the input of the command
+func commandArguments(command CommandType) (abi.Arguments, error) {
+	var types []string
+	switch command &^ CommandAllowRevert {
+	case CommandSweep, CommandPayPortion:
+		types = []string{"address", "address", "uint256"}
+	case CommandV2SwapExactIn, CommandV2SwapExactOut:
+		types = []string{"address", "uint256", "uint256", "address[]", "bool"}
+	case CommandWrapETH, CommandUnwrapWETH:
+		types = []string{"address", "uint256"}
+	default:
+		return nil, fmt.Errorf("%w: %#x", ErrUnknownCommand, byte(command))
+	}
+	arguments := make(abi.Arguments, len(types))
+	for i, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		arguments[i] = abi.Argument{Type: typ}
+	}
+	return arguments, nil
+}
+
+// AddCommand appends the command with its ABI encoded arguments
+func (p *ExecuteParameters) AddCommand(command CommandType, args ...interface{}) error {
+	arguments, err := commandArguments(command)
+	if err != nil {
+		return err
+	}
+	input, err := arguments.Pack(args...)
+	if err != nil {
+		return err
+	}
+	p.Commands = append(p.Commands, byte(command))
+	p.Inputs = append(p.Inputs, input)
+	return nil
+}
+
+// UnpackCommand decodes the input of the command
+func UnpackCommand(command CommandType, input []byte) ([]interface{}, error) {
+	arguments, err := commandArguments(command)
+	if err != nil {
+		return nil, err
+	}
+	return arguments.Unpack(input)
+}
+
+// Pack encodes the execute call with the Universal Router ABI
+func (p *ExecuteParameters) Pack() ([]byte, error) {
+	universalABI, err := abi.JSON(strings.NewReader(UniversalRouterABI))
+	if err != nil {
+		return nil, err
+	}
+	return universalABI.Pack("execute", p.Commands, p.Inputs, p.Deadline)
+}
+
+// Call packs the execute call into a step sent to the Universal Router
+func (p *ExecuteParameters) Call(router common.Address, gas uint64) (*Call, error) {
+	data, err := p.Pack()
+	if err != nil {
+		return nil, err
+	}
+	value := p.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return &Call{To: router, Data: data, Value: value, Gas: gas}, nil
+}
+
+// bips returns the fee in basis points
+func (f *FeeOptions) bips() (*big.Int, error) {
+	if f.Fee == nil {
+		return nil, ErrInvalidFee
+	}
+	bips, rem := new(big.Int).QuoRem(new(big.Int).Mul(f.Fee.Numerator, big.NewInt(MaxBips)), f.Fee.Denominator, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, ErrInvalidFee
+	}
+	if bips.Sign() < 0 || bips.Cmp(big.NewInt(MaxBips)) > 0 {
+		return nil, ErrInvalidFee
+	}
+	return bips, nil
+}
+
+// UniversalSwapCallParameters produces the Universal Router commands executing a trade.
+// Ether input is wrapped by the router and ether output unwrapped, as SwapCallParameters uses the ETH methods.
+// The input token is paid by the caller through Permit2, so the router needs a Permit2 allowance rather than an
+// approval of its own, which options.Permit grants in the same call.
+func UniversalSwapCallParameters(trade *entities.Trade, options UniversalOptions) (*ExecuteParameters, error) {
+	etherIn := trade.InputAmount().Currency.IsNative() && !options.WrappedInput
+	etherOut := trade.OutputAmount().Currency.IsNative() && !options.WrappedOutput
+	if etherIn && etherOut {
+		return nil, ErrEtherInOut
+	}
+	if err := resolveCurrencies(trade); err != nil {
+		return nil, err
+	}
+	if trade.TradeType == entities.ExactOutput && options.FeeOnTransfer {
+		// V2_SWAP_EXACT_OUT computes the input from the reserves, which a transfer fee makes insufficient
+		return nil, ErrExactOutFot
+	}
+	slippage, err := slippageOrZero(options.AllowedSlippage)
+	if err != nil {
+		return nil, err
+	}
+	maxAmountIn, err := trade.MaximumAmountIn(slippage)
+	if err != nil {
+		return nil, err
+	}
+	amountIn := maxAmountIn.Quotient()
+	minAmountOut, err := trade.MinimumAmountOut(slippage)
+	if err != nil {
+		return nil, err
+	}
+	amountOut := minAmountOut.Quotient()
+	if err := checkLimits(trade, options.TradeOptions, minAmountOut); err != nil {
+		return nil, err
+	}
+	var bips *big.Int
+	if options.Fee != nil {
+		if bips, err = options.Fee.bips(); err != nil {
+			return nil, err
+		}
+	}
+	var path []common.Address
+	for _, token := range trade.Route.Path {
+		path = append(path, token.Address)
+	}
+	deadline, err := options.deadline()
+	if err != nil {
+		return nil, err
+	}
+
+	params := &ExecuteParameters{Deadline: deadline, Value: big.NewInt(0)}
+	if options.Permit != nil {
+		details := options.Permit.Permit.Details
+		if etherIn || details.Token != path[0] || details.Amount == nil || details.Amount.Cmp(amountIn) < 0 {
+			return nil, ErrPermitMismatch
+		}
+		if err := params.AddPermit(options.Permit.Permit, options.Permit.Signature); err != nil {
+			return nil, err
+		}
+	}
+	if etherIn {
+		params.Value = amountIn
+		if err := params.AddCommand(CommandWrapETH, AddressThis, amountIn); err != nil {
+			return nil, err
+		}
+	}
+	// the router keeps the output if it has to unwrap it or take the fee from it
+	swapRecipient := options.Recipient
+	if etherOut || bips != nil {
+		swapRecipient = AddressThis
+	}
+	// the caller pays the input token, the router pays the ether it wrapped
+	payerIsUser := !etherIn
+	switch trade.TradeType {
+	case entities.ExactInput:
+		err = params.AddCommand(CommandV2SwapExactIn, swapRecipient, amountIn, amountOut, path, payerIsUser)
+	case entities.ExactOutput:
+		err = params.AddCommand(CommandV2SwapExactOut, swapRecipient, amountOut, amountIn, path, payerIsUser)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	output := path[len(path)-1]
+	if etherOut {
+		unwrapRecipient := options.Recipient
+		if bips != nil {
+			unwrapRecipient = AddressThis
+			output = NativeAddress
+		}
+		if err := params.AddCommand(CommandUnwrapWETH, unwrapRecipient, amountOut); err != nil {
+			return nil, err
+		}
+	}
+	if bips != nil {
+		if err := params.AddCommand(CommandPayPortion, output, options.Fee.Recipient, bips); err != nil {
+			return nil, err
+		}
+		// the recipient gets the rest, at least the minimum output less the fee
+		sweepMin := new(big.Int).Mul(amountOut, new(big.Int).Sub(big.NewInt(MaxBips), bips))
+		sweepMin.Quo(sweepMin, big.NewInt(MaxBips))
+		if err := params.AddCommand(CommandSweep, output, options.Recipient, sweepMin); err != nil {
+			return nil, err
+		}
+	}
+	if etherIn && trade.TradeType == entities.ExactOutput {
+		// refund the ether wrapped but not spent
+		if err := params.AddCommand(CommandUnwrapWETH, MsgSender, big.NewInt(0)); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,168 @@
+package router_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func universalOptions() router.UniversalOptions {
+	return router.UniversalOptions{
+		TradeOptions: router.TradeOptions{AllowedSlippage: slippage, Recipient: recipient, Deadline: deadline},
+	}
+}
+
+func TestUniversalExactInEtherToToken1(t *testing.T) {
+	testNumber = 0
+	route, _ := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1}, ether, token1)
+	trade, err := entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.UniversalSwapCallParameters(trade, universalOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x0b08", hexutil.Encode(params.Commands))
+	check(t, big.NewInt(100), params.Value)
+	check(t, deadline, params.Deadline)
+
+	wrap, _ := router.UnpackCommand(router.CommandWrapETH, params.Inputs[0])
+	check(t, router.AddressThis, wrap[0])
+	check(t, big.NewInt(100), wrap[1])
+
+	swap, _ := router.UnpackCommand(router.CommandV2SwapExactIn, params.Inputs[1])
+	check(t, recipient, swap[0])
+	check(t, big.NewInt(100), swap[1])
+	check(t, hexutil.MustDecodeBig("0x51"), swap[2])
+	check(t, []common.Address{core.WETH9[1].Address, token0.Address, token1.Address}, swap[3])
+	check(t, false, swap[4])
+
+	data, err := params.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x3593564c", hexutil.Encode(data[:4]))
+}
+
+func TestUniversalExactInToken1ToEther(t *testing.T) {
+	testNumber = 0
+	route, _ := entities.NewRoute([]*entities.Pair{pair_0_1, pair_weth_0}, token1, ether)
+	trade, err := entities.ExactIn(route, core.FromRawAmount(token1, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.UniversalSwapCallParameters(trade, universalOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x080c", hexutil.Encode(params.Commands))
+	check(t, big.NewInt(0), params.Value)
+
+	swap, _ := router.UnpackCommand(router.CommandV2SwapExactIn, params.Inputs[0])
+	check(t, router.AddressThis, swap[0])
+	check(t, true, swap[4])
+
+	unwrap, _ := router.UnpackCommand(router.CommandUnwrapWETH, params.Inputs[1])
+	check(t, recipient, unwrap[0])
+	check(t, hexutil.MustDecodeBig("0x51"), unwrap[1])
+}
+
+func TestUniversalExactOutEtherToToken1(t *testing.T) {
+	testNumber = 0
+	route, _ := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1}, ether, token1)
+	trade, err := entities.ExactOut(route, core.FromRawAmount(token1, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := router.UniversalSwapCallParameters(trade, universalOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wrap the maximum input, swap, refund the rest
+	check(t, "0x0b090c", hexutil.Encode(params.Commands))
+	check(t, hexutil.MustDecodeBig("0x80"), params.Value)
+
+	swap, _ := router.UnpackCommand(router.CommandV2SwapExactOut, params.Inputs[1])
+	check(t, recipient, swap[0])
+	check(t, big.NewInt(100), swap[1])
+	check(t, hexutil.MustDecodeBig("0x80"), swap[2])
+
+	refund, _ := router.UnpackCommand(router.CommandUnwrapWETH, params.Inputs[2])
+	check(t, router.MsgSender, refund[0])
+	check(t, "0", refund[1].(*big.Int).String())
+}
+
+func TestUniversalFee(t *testing.T) {
+	testNumber = 0
+	feeRecipient := common.HexToAddress("0x0000000000000000000000000000000000000005")
+	route, _ := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	trade, err := entities.ExactIn(route, core.FromRawAmount(token0, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := universalOptions()
+	options.Fee = &router.FeeOptions{Fee: core.NewPercent(big.NewInt(25), big.NewInt(10000)), Recipient: feeRecipient}
+	params, err := router.UniversalSwapCallParameters(trade, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x080604", hexutil.Encode(params.Commands))
+
+	swap, _ := router.UnpackCommand(router.CommandV2SwapExactIn, params.Inputs[0])
+	check(t, router.AddressThis, swap[0])
+	check(t, big.NewInt(89), swap[2])
+
+	pay, _ := router.UnpackCommand(router.CommandPayPortion, params.Inputs[1])
+	check(t, token1.Address, pay[0])
+	check(t, feeRecipient, pay[1])
+	check(t, big.NewInt(25), pay[2])
+
+	sweep, _ := router.UnpackCommand(router.CommandSweep, params.Inputs[2])
+	check(t, token1.Address, sweep[0])
+	check(t, recipient, sweep[1])
+	check(t, big.NewInt(88), sweep[2])
+
+	// fee taken in ether after unwrapping
+	route, _ = entities.NewRoute([]*entities.Pair{pair_0_1, pair_weth_0}, token1, ether)
+	trade, _ = entities.ExactIn(route, core.FromRawAmount(token1, big.NewInt(100)))
+	params, err = router.UniversalSwapCallParameters(trade, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "0x080c0604", hexutil.Encode(params.Commands))
+	unwrap, _ := router.UnpackCommand(router.CommandUnwrapWETH, params.Inputs[1])
+	check(t, router.AddressThis, unwrap[0])
+	pay, _ = router.UnpackCommand(router.CommandPayPortion, params.Inputs[2])
+	check(t, router.NativeAddress, pay[0])
+
+	// fractional basis points
+	options.Fee.Fee = core.NewPercent(big.NewInt(1), big.NewInt(100000))
+	_, err = router.UniversalSwapCallParameters(trade, options)
+	check(t, router.ErrInvalidFee, err)
+}
+
+func TestUniversalErrors(t *testing.T) {
+	testNumber = 0
+	route, _ := entities.NewRoute([]*entities.Pair{pair_0_1}, token0, token1)
+	trade, _ := entities.ExactOut(route, core.FromRawAmount(token1, big.NewInt(100)))
+	options := universalOptions()
+	options.FeeOnTransfer = true
+	_, err := router.UniversalSwapCallParameters(trade, options)
+	check(t, router.ErrExactOutFot, err)
+
+	// fee on transfer is supported exact in
+	trade, _ = entities.ExactIn(route, core.FromRawAmount(token0, big.NewInt(100)))
+	params, err := router.UniversalSwapCallParameters(trade, options)
+	check(t, nil, err)
+	check(t, "0x08", hexutil.Encode(params.Commands))
+
+	_, err = router.UnpackCommand(0x3f, nil)
+	check(t, true, errors.Is(err, router.ErrUnknownCommand))
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+const WETH9ABI = "[ { \"inputs\": [], \"name\": \"deposit\", \"outputs\": [], \"stateMutability\": \"payable\", \"type\": \"function\" }, { \"inputs\": [ { \"internalType\": \"uint256\", \"name\": \"wad\", \"type\": \"uint256\" } ], \"name\": \"withdraw\", \"outputs\": [], \"stateMutability\": \"nonpayable\", \"type\": \"function\" } ]"
+
+const (
+	DepositGas  uint64 = 50000 // DepositGas is the gas limit of a WETH deposit call
+	WithdrawGas uint64 = 50000 // WithdrawGas is the gas limit of a WETH withdraw call
+)
+
+var ErrNotWrap = errors.New("currencies are not the native currency and its wrapped token")
+
+// DepositCalldata returns the calldata of deposit(), which wraps the wei sent with the call
+func DepositCalldata() ([]byte, error) {
+	wethABI, err := abi.JSON(strings.NewReader(WETH9ABI))
+	if err != nil {
+		return nil, err
+	}
+	return wethABI.Pack("deposit")
+}
+
+// WithdrawCalldata returns the calldata of withdraw(wad), which unwraps the amount
+func WithdrawCalldata(amount *big.Int) ([]byte, error) {
+	wethABI, err := abi.JSON(strings.NewReader(WETH9ABI))
+	if err != nil {
+		return nil, err
+	}
+	return wethABI.Pack("withdraw", amount)
+}
+
+// WrapCall returns the call converting the amount 1:1 into the other currency: a deposit to the wrapped token
+// if the amount is native, a withdrawal from it otherwise.
+// Returns ErrNotWrap unless the currencies are the native currency and its wrapped token.
+func WrapCall(amountIn *core.CurrencyAmount, currencyOut core.Currency) (*Call, error) {
+	if !entities.IsWrap(amountIn.Currency, currencyOut) {
+		return nil, ErrNotWrap
+	}
+	weth := entities.WrappedOf(currencyOut).Address
+	if amountIn.Currency.IsNative() {
+		data, err := DepositCalldata()
+		if err != nil {
+			return nil, err
+		}
+		return &Call{To: weth, Data: data, Value: amountIn.Quotient(), Gas: DepositGas}, nil
+	}
+	data, err := WithdrawCalldata(amountIn.Quotient())
+	if err != nil {
+		return nil, err
+	}
+	return &Call{To: weth, Data: data, Value: big.NewInt(0), Gas: WithdrawGas}, nil
+}
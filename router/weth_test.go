@@ -0,0 +1,94 @@
+package router_test
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+func TestWrapCall(t *testing.T) {
+	testNumber = 0
+	weth := core.WETH9[1]
+
+	deposit, err := router.WrapCall(core.FromRawAmount(ether, big.NewInt(100)), weth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, weth.Address, deposit.To)
+	check(t, "0xd0e30db0", hexutil.Encode(deposit.Data))
+	check(t, big.NewInt(100), deposit.Value)
+
+	withdraw, err := router.WrapCall(core.FromRawAmount(weth, big.NewInt(100)), ether)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, weth.Address, withdraw.To)
+	check(t, "0x2e1a7d4d0000000000000000000000000000000000000000000000000000000000000064", hexutil.Encode(withdraw.Data))
+	check(t, big.NewInt(0), withdraw.Value)
+
+	// core.Ether of a chain without core.WETH9 unwraps from the registered wrapped token
+	wmatic := entities.NativeCurrencies[137].Wrapped()
+	withdraw, err = router.WrapCall(core.FromRawAmount(wmatic, big.NewInt(100)), core.EtherOnChain(137))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, wmatic.Address, withdraw.To)
+
+	_, err = router.WrapCall(core.FromRawAmount(ether, big.NewInt(100)), token0)
+	check(t, router.ErrNotWrap, err)
+	_, err = router.WrapCall(core.FromRawAmount(weth, big.NewInt(100)), weth)
+	check(t, router.ErrNotWrap, err)
+}
+
+func TestWrappedOutput(t *testing.T) {
+	testNumber = 0
+	pair_1_weth, _ := entities.NewPair(amount1, ethAmount, nil)
+	route, err := entities.NewRoute([]*entities.Pair{pair_weth_0, pair_0_1, pair_1_weth}, ether, ether)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade, err := entities.ExactIn(route, core.FromRawAmount(ether, big.NewInt(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := router.TradeOptions{AllowedSlippage: slippage, Recipient: recipient, Deadline: deadline}
+
+	// ether in and out
+	_, err = router.SwapCallParameters(trade, options)
+	check(t, router.ErrEtherInOut, err)
+
+	// ether in, WETH out
+	options.WrappedOutput = true
+	params, err := router.SwapCallParameters(trade, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "swapExactETHForTokens", params.MethodName)
+	check(t, core.WETH9[1].Address, params.Args[1].([]common.Address)[3])
+	check(t, big.NewInt(100), params.Value)
+
+	// WETH in, ether out
+	options.WrappedOutput = false
+	options.WrappedInput = true
+	params, err = router.SwapCallParameters(trade, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "swapExactTokensForETH", params.MethodName)
+	check(t, big.NewInt(0), params.Value)
+
+	// WETH input is approved
+	calls, err := router.PlanSwap(trade, options, router.PlanOptions{Router: routerAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approve, _ := router.ApproveCalldata(routerAddress, big.NewInt(100))
+	check(t, 2, len(calls))
+	check(t, core.WETH9[1].Address, calls[0].To)
+	check(t, approve, calls[0].Data)
+}
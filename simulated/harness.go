@@ -0,0 +1,326 @@
+// Package simulated runs the canonical Uniswap V2 contracts in go-ethereum's in-process simulated backend, so that
+// quotes and calldata produced by this SDK can be checked against the contracts themselves.
+//
+// The runtime bytecode of the factory, Router02 and WETH9 deployed on mainnet is embedded from contracts/, and every
+// harness places it at the mainnet addresses of a fresh simulated chain. Router02 refers to the factory and WETH9 by
+// those addresses, which its bytecode holds as immutables.
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	ethcore "github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+)
+
+const (
+	GasLimit uint64 = 30000000 // Block gas limit of the simulated chain
+
+	factoryABI = "[ { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"tokenA\", \"type\": \"address\" }, { \"internalType\": \"address\", \"name\": \"tokenB\", \"type\": \"address\" } ], \"name\": \"createPair\", \"outputs\": [ { \"internalType\": \"address\", \"name\": \"pair\", \"type\": \"address\" } ], \"stateMutability\": \"nonpayable\", \"type\": \"function\" }, { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" }, { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" } ], \"name\": \"getPair\", \"outputs\": [ { \"internalType\": \"address\", \"name\": \"\", \"type\": \"address\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+	pairABI    = "[ { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"to\", \"type\": \"address\" } ], \"name\": \"mint\", \"outputs\": [ { \"internalType\": \"uint256\", \"name\": \"liquidity\", \"type\": \"uint256\" } ], \"stateMutability\": \"nonpayable\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"getReserves\", \"outputs\": [ { \"internalType\": \"uint112\", \"name\": \"reserve0\", \"type\": \"uint112\" }, { \"internalType\": \"uint112\", \"name\": \"reserve1\", \"type\": \"uint112\" }, { \"internalType\": \"uint32\", \"name\": \"blockTimestampLast\", \"type\": \"uint32\" } ], \"stateMutability\": \"view\", \"type\": \"function\" } ]"
+	tokenABI   = "[ { \"inputs\": [ { \"internalType\": \"address\", \"name\": \"to\", \"type\": \"address\" }, { \"internalType\": \"uint256\", \"name\": \"value\", \"type\": \"uint256\" } ], \"name\": \"transfer\", \"outputs\": [ { \"internalType\": \"bool\", \"name\": \"\", \"type\": \"bool\" } ], \"stateMutability\": \"nonpayable\", \"type\": \"function\" }, { \"inputs\": [], \"name\": \"deposit\", \"outputs\": [], \"stateMutability\": \"payable\", \"type\": \"function\" } ]"
+)
+
+var (
+	ErrReverted       = errors.New("transaction reverted")
+	ErrOutputMismatch = errors.New("received amount differs from the trade output")
+	ErrPairMismatch   = errors.New("deployed pair address differs from GetAddress")
+)
+
+// Mainnet addresses of the contracts, where every harness places them
+var (
+	FactoryAddress = entities.FactoryAddress
+	RouterAddress  = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	WETH9Address   = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+)
+
+// Runtime bytecode of the contracts on mainnet
+var (
+	//go:embed contracts/UniswapV2Factory.hex
+	factoryCode string
+	//go:embed contracts/UniswapV2Router02.hex
+	routerCode string
+	//go:embed contracts/WETH9.hex
+	weth9Code string
+)
+
+// Harness is a simulated chain with the factory, WETH9 and Router02 at their mainnet addresses and a funded account.
+// Its tokens and currencies are its own: nothing is registered in core.WETH9 or entities.NativeCurrencies, so
+// harnesses do not interfere with each other or with other users of the chain ID.
+type Harness struct {
+	Backend *backends.SimulatedBackend
+	ChainID *big.Int
+	Key     *ecdsa.PrivateKey
+	From    common.Address // The funded account, owner of every token minted by the harness
+	Factory common.Address
+	Router  common.Address
+	WETH9   *core.Token              // WETH9 on the simulated chain
+	Ether   *entities.NativeCurrency // The native currency of the simulated chain, wrapped by WETH9
+
+	factory *bind.BoundContract
+}
+
+// New starts a fresh simulated chain with the contracts in place
+func New() (*Harness, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	funds := new(big.Int).Lsh(big.NewInt(1), 128)
+	backend := backends.NewSimulatedBackend(ethcore.GenesisAlloc{
+		from:           {Balance: funds},
+		FactoryAddress: {Code: common.FromHex(strings.TrimSpace(factoryCode)), Balance: new(big.Int)},
+		RouterAddress:  {Code: common.FromHex(strings.TrimSpace(routerCode)), Balance: new(big.Int)},
+		WETH9Address:   {Code: common.FromHex(strings.TrimSpace(weth9Code)), Balance: new(big.Int)},
+	}, GasLimit)
+	chainID := backend.Blockchain().Config().ChainID
+	parsed, err := abi.JSON(strings.NewReader(factoryABI))
+	if err != nil {
+		return nil, err
+	}
+	weth := core.NewToken(uint(chainID.Uint64()), WETH9Address, 18, "WETH", "Wrapped Ether")
+	return &Harness{
+		Backend: backend,
+		ChainID: chainID,
+		Key:     key,
+		From:    from,
+		Factory: FactoryAddress,
+		Router:  RouterAddress,
+		WETH9:   weth,
+		Ether:   entities.NewNativeCurrency(weth, 18, "ETH", "Ether"),
+		factory: bind.NewBoundContract(FactoryAddress, parsed, backend, backend, backend),
+	}, nil
+}
+
+// creationCode returns the contract creation code that deploys the runtime bytecode as is:
+// CODECOPY the runtime after the 12 bytes of this prefix to memory and RETURN it
+func creationCode(runtime []byte) []byte {
+	prefix := []byte{0x61, byte(len(runtime) >> 8), byte(len(runtime)), 0x80, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(prefix, runtime...)
+}
+
+func (h *Harness) opts(value *big.Int) (*bind.TransactOpts, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(h.Key, h.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Value = value
+	opts.GasLimit = GasLimit / 2
+	return opts, nil
+}
+
+// mined commits the block holding the transaction and fails if it reverted
+func (h *Harness) mined(tx *types.Transaction) (*types.Receipt, error) {
+	h.Backend.Commit()
+	receipt, err := h.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, ErrReverted
+	}
+	return receipt, nil
+}
+
+func (h *Harness) deploy(parsed abi.ABI, bytecode []byte, params ...interface{}) (common.Address, *bind.BoundContract, error) {
+	opts, err := h.opts(nil)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	address, tx, contract, err := bind.DeployContract(opts, parsed, bytecode, h.Backend, params...)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if _, err := h.mined(tx); err != nil {
+		return common.Address{}, nil, err
+	}
+	return address, contract, nil
+}
+
+// transact sends the calldata to the contract and waits for it to be mined
+func (h *Harness) transact(to common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	opts, err := h.opts(value)
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(to, abi.ABI{}, h.Backend, h.Backend, h.Backend)
+	tx, err := contract.RawTransact(opts, data)
+	if err != nil {
+		return nil, err
+	}
+	return h.mined(tx)
+}
+
+func (h *Harness) transactMethod(to common.Address, abiJSON string, method string, value *big.Int, args ...interface{}) (*types.Receipt, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return h.transact(to, value, data)
+}
+
+// DeployToken deploys a test token with 18 decimals, a copy of WETH9, and mints the supply to the harness account
+// by depositing as much ether
+func (h *Harness) DeployToken(symbol string, supply *big.Int) (*core.Token, error) {
+	address, _, err := h.deploy(abi.ABI{}, creationCode(common.FromHex(strings.TrimSpace(weth9Code))))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.transactMethod(address, tokenABI, "deposit", supply); err != nil {
+		return nil, err
+	}
+	return core.NewToken(uint(h.ChainID.Uint64()), address, 18, symbol, symbol), nil
+}
+
+// CreatePair creates the pair of the amounts and mints its first liquidity with them.
+// Fails with ErrPairMismatch if the factory deploys the pair elsewhere than GetAddress predicts.
+func (h *Harness) CreatePair(amountA, amountB *core.CurrencyAmount) (*entities.Pair, error) {
+	tokenA, tokenB := entities.WrappedOf(amountA.Currency), entities.WrappedOf(amountB.Currency)
+	if _, err := h.transactMethod(h.Factory, factoryABI, "createPair", nil, tokenA.Address, tokenB.Address); err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	if err := h.factory.Call(&bind.CallOpts{}, &out, "getPair", tokenA.Address, tokenB.Address); err != nil {
+		return nil, err
+	}
+	deployed := out[0].(common.Address)
+	predicted, err := entities.GetAddress(tokenA, tokenB, h.Factory, entities.InitCodeHash)
+	if err != nil {
+		return nil, err
+	}
+	if deployed != predicted {
+		return nil, fmt.Errorf("%w: %s != %s", ErrPairMismatch, deployed.Hex(), predicted.Hex())
+	}
+
+	for _, amount := range []*core.CurrencyAmount{amountA, amountB} {
+		token := entities.WrappedOf(amount.Currency)
+		if token.Equal(h.WETH9) {
+			if _, err := h.transactMethod(token.Address, tokenABI, "deposit", amount.Quotient()); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := h.transactMethod(token.Address, tokenABI, "transfer", nil, deployed, amount.Quotient()); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := h.transactMethod(deployed, pairABI, "mint", nil, h.From); err != nil {
+		return nil, err
+	}
+	return entities.NewPair(
+		core.FromRawAmount(tokenA, amountA.Quotient()),
+		core.FromRawAmount(tokenB, amountB.Quotient()),
+		&entities.PairOptions{Factory: h.Factory, InitCodeHash: entities.InitCodeHash, Address: &deployed},
+	)
+}
+
+// Reload returns the pair with its reserves read from the chain, as they are after the trades executed so far
+func (h *Harness) Reload(pair *entities.Pair) (*entities.Pair, error) {
+	parsed, err := abi.JSON(strings.NewReader(pairABI))
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(pair.Address, parsed, h.Backend, h.Backend, h.Backend)
+	var out []interface{}
+	if err := contract.Call(&bind.CallOpts{}, &out, "getReserves"); err != nil {
+		return nil, err
+	}
+	return entities.NewPair(
+		core.FromRawAmount(pair.Token0(), out[0].(*big.Int)),
+		core.FromRawAmount(pair.Token1(), out[1].(*big.Int)),
+		pair.Options,
+	)
+}
+
+// Approve allows the router to spend the token of the harness account
+func (h *Harness) Approve(token common.Address, amount *big.Int) error {
+	data, err := router.ApproveCalldata(h.Router, amount)
+	if err != nil {
+		return err
+	}
+	_, err = h.transact(token, nil, data)
+	return err
+}
+
+// BalanceOf returns the balance of the account, in wei for the native currency
+func (h *Harness) BalanceOf(currency core.Currency, account common.Address) (*big.Int, error) {
+	ctx := context.Background()
+	if currency.IsNative() {
+		return h.Backend.BalanceAt(ctx, account, nil)
+	}
+	parsed, err := abi.JSON(strings.NewReader(router.ERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("balanceOf", account)
+	if err != nil {
+		return nil, err
+	}
+	token := entities.WrappedOf(currency).Address
+	result, err := h.Backend.CallContract(ctx, ethereum.CallMsg{From: h.From, To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// Execute sends the router call produced by SwapCallParametersPacked
+func (h *Harness) Execute(value *big.Int, data []byte) (*types.Receipt, error) {
+	return h.transact(h.Router, value, data)
+}
+
+// VerifyTrade executes the trade through the router, approving its input first, and checks that the recipient
+// receives exactly the trade output. The trade must be built from pairs returned by CreatePair and the options
+// should allow no slippage.
+func (h *Harness) VerifyTrade(trade *entities.Trade, options router.TradeOptions) error {
+	input := trade.InputAmount()
+	if !input.Currency.IsNative() {
+		amountIn, err := trade.MaximumAmountIn(core.NewPercent(big.NewInt(0), big.NewInt(1)))
+		if err != nil {
+			return err
+		}
+		if err := h.Approve(entities.WrappedOf(input.Currency).Address, amountIn.Quotient()); err != nil {
+			return err
+		}
+	}
+	output := trade.OutputAmount().Currency
+	before, err := h.BalanceOf(output, options.Recipient)
+	if err != nil {
+		return err
+	}
+	value, data, err := router.SwapCallParametersPacked(trade, options)
+	if err != nil {
+		return err
+	}
+	if _, err := h.Execute(value, data); err != nil {
+		return err
+	}
+	after, err := h.BalanceOf(output, options.Recipient)
+	if err != nil {
+		return err
+	}
+	received := new(big.Int).Sub(after, before)
+	if received.Cmp(trade.OutputAmount().Quotient()) != 0 {
+		return fmt.Errorf("%w: received %s, expected %s", ErrOutputMismatch, received, trade.OutputAmount().Quotient())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package simulated_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/router"
+	"github.com/vaulverin/uniswapv2-sdk/simulated"
+)
+
+// TestRouter02 executes trades against the canonical contracts
+func TestRouter02(t *testing.T) {
+	h, err := simulated.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the harness keeps its currencies to itself
+	if _, ok := entities.NativeCurrencies[uint(h.ChainID.Uint64())]; ok || core.WETH9[uint(h.ChainID.Uint64())] != nil {
+		t.Errorf("expect[no registered currencies of chain %s]", h.ChainID)
+	}
+	supply := new(big.Int).Lsh(big.NewInt(1), 100)
+	token0, err := h.DeployToken("t0", supply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := h.DeployToken("t1", supply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	pair_0_1, err := h.CreatePair(
+		core.FromRawAmount(token0, new(big.Int).Mul(big.NewInt(1000), e18)),
+		core.FromRawAmount(token1, new(big.Int).Mul(big.NewInt(2000), e18)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair_weth_0, err := h.CreatePair(
+		core.FromRawAmount(h.WETH9, new(big.Int).Mul(big.NewInt(10), e18)),
+		core.FromRawAmount(token0, new(big.Int).Mul(big.NewInt(1000), e18)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ether := h.Ether
+	recipient := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	options := router.TradeOptions{
+		AllowedSlippage: core.NewPercent(big.NewInt(0), big.NewInt(1)),
+		Recipient:       recipient,
+		Deadline:        big.NewInt(time.Now().Add(time.Hour).Unix()),
+	}
+	amount := new(big.Int).Mul(big.NewInt(3), e18)
+	tests := []struct {
+		Name  string
+		Pairs []*entities.Pair
+		In    core.Currency
+		Out   core.Currency
+		Type  entities.TradeType
+	}{
+		{"exact in token to token", []*entities.Pair{pair_0_1}, token0, token1, entities.ExactInput},
+		{"exact out token to token", []*entities.Pair{pair_0_1}, token1, token0, entities.ExactOutput},
+		{"exact in ether to token", []*entities.Pair{pair_weth_0, pair_0_1}, ether, token1, entities.ExactInput},
+		{"exact out token to ether", []*entities.Pair{pair_0_1, pair_weth_0}, token1, ether, entities.ExactOutput},
+	}
+	for _, test := range tests {
+		pairs := make([]*entities.Pair, len(test.Pairs))
+		for i, pair := range test.Pairs {
+			if pairs[i], err = h.Reload(pair); err != nil {
+				t.Fatal(err)
+			}
+		}
+		route, err := entities.NewRoute(pairs, test.In, test.Out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var trade *entities.Trade
+		if test.Type == entities.ExactInput {
+			trade, err = entities.ExactIn(route, core.FromRawAmount(test.In, amount))
+		} else {
+			trade, err = entities.ExactOut(route, core.FromRawAmount(test.Out, amount))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.VerifyTrade(trade, options); err != nil {
+			t.Errorf("%s: %v", test.Name, err)
+		}
+	}
+}
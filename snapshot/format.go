@@ -0,0 +1,466 @@
+// Package snapshot stores sets of pairs at a block in a compact, versioned binary format, and diffs between them.
+//
+// A stream is a header, then records, then a footer:
+//
+//	header  "UV2S" | version byte | kind byte | chain id uvarint | block uvarint | base block uvarint (diffs only)
+//	token   0x01 | address [20] | decimals byte | symbol string | name string
+//	factory 0x02 | address [20] | init code hash string
+//	pair    0x03 | address [20] | token0 uvarint | token1 uvarint | factory uvarint | fee uvarint | reserve0 int | reserve1 int
+//	removal 0x04 | address [20]
+//	footer  0x00 | pair and removal count uvarint | CRC-32C of all the preceding bytes [4]
+//
+// Strings and ints are a uvarint length followed by the bytes, ints big-endian. Tokens and factories are numbered
+// in the order they appear and are written once, before the first pair referring to them.
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+// Version is the version of the format written
+const Version byte = 1
+
+// DefaultFee is the swap fee of Uniswap V2 pairs, in bips
+const DefaultFee uint32 = 30
+
+// Kind of a stream
+type Kind byte
+
+const (
+	Full Kind = 1 // All the pairs at a block
+	Diff Kind = 2 // The pairs added, changed or removed since a base block
+)
+
+var magic = []byte("UV2S")
+
+const (
+	tagEnd byte = iota
+	tagToken
+	tagFactory
+	tagPair
+	tagRemoval
+)
+
+var (
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+	ErrVersion         = errors.New("unsupported snapshot version")
+	ErrChecksum        = errors.New("snapshot checksum mismatch")
+	ErrRemovalInFull   = errors.New("full snapshots have no removals")
+	ErrClosed          = errors.New("snapshot writer is closed")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Header describes a stream
+type Header struct {
+	Version   byte
+	Kind      Kind
+	ChainID   uint
+	Block     uint64
+	BaseBlock uint64 // The block a diff applies to
+}
+
+// Entry is a pair of a snapshot, or its removal in a diff
+type Entry struct {
+	Address common.Address
+	Pair    *entities.Pair // Nil if removed
+	Fee     uint32         // The swap fee in bips, DefaultFee for Uniswap V2
+}
+
+// Writer streams a snapshot
+type Writer struct {
+	header    Header
+	w         *bufio.Writer
+	crc       hash.Hash32
+	out       io.Writer
+	tokens    map[common.Address]uint64
+	factories map[string]uint64
+	count     uint64
+	closed    bool
+	buf       []byte
+}
+
+// NewWriter writes the header of a stream. Close must be called to complete it.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	if header.Kind != Full && header.Kind != Diff {
+		return nil, fmt.Errorf("%w: kind %d", ErrInvalidSnapshot, header.Kind)
+	}
+	header.Version = Version
+	sw := &Writer{
+		header:    header,
+		w:         bufio.NewWriter(w),
+		crc:       crc32.New(castagnoli),
+		tokens:    map[common.Address]uint64{},
+		factories: map[string]uint64{},
+	}
+	sw.out = io.MultiWriter(sw.w, sw.crc)
+	sw.buf = append(sw.buf, magic...)
+	sw.buf = append(sw.buf, header.Version, byte(header.Kind))
+	sw.buf = appendUvarint(sw.buf, uint64(header.ChainID))
+	sw.buf = appendUvarint(sw.buf, header.Block)
+	if header.Kind == Diff {
+		sw.buf = appendUvarint(sw.buf, header.BaseBlock)
+	}
+	if err := sw.flush(); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+// Header returns the header written
+func (w *Writer) Header() Header {
+	return w.header
+}
+
+func (w *Writer) flush() error {
+	_, err := w.out.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func (w *Writer) token(token *core.Token) uint64 {
+	if index, ok := w.tokens[token.Address]; ok {
+		return index
+	}
+	index := uint64(len(w.tokens))
+	w.tokens[token.Address] = index
+	w.buf = append(w.buf, tagToken)
+	w.buf = append(w.buf, token.Address.Bytes()...)
+	w.buf = append(w.buf, byte(token.Decimals()))
+	w.buf = appendBytes(w.buf, []byte(token.Symbol()))
+	w.buf = appendBytes(w.buf, []byte(token.Name()))
+	return index
+}
+
+func pairOptions(pair *entities.Pair) (common.Address, []byte) {
+	if pair.Options == nil {
+		return entities.FactoryAddress, entities.InitCodeHash
+	}
+	return pair.Options.Factory, pair.Options.InitCodeHash
+}
+
+func (w *Writer) factory(pair *entities.Pair) uint64 {
+	factory, initCodeHash := pairOptions(pair)
+	key := string(factory.Bytes()) + string(initCodeHash)
+	if index, ok := w.factories[key]; ok {
+		return index
+	}
+	index := uint64(len(w.factories))
+	w.factories[key] = index
+	w.buf = append(w.buf, tagFactory)
+	w.buf = append(w.buf, factory.Bytes()...)
+	w.buf = appendBytes(w.buf, initCodeHash)
+	return index
+}
+
+// WritePair writes the pair with its swap fee in bips
+func (w *Writer) WritePair(pair *entities.Pair, fee uint32) error {
+	if w.closed {
+		return ErrClosed
+	}
+	token0, token1 := w.token(pair.Token0()), w.token(pair.Token1())
+	factory := w.factory(pair)
+	w.buf = append(w.buf, tagPair)
+	w.buf = append(w.buf, pair.Address.Bytes()...)
+	w.buf = appendUvarint(w.buf, token0)
+	w.buf = appendUvarint(w.buf, token1)
+	w.buf = appendUvarint(w.buf, factory)
+	w.buf = appendUvarint(w.buf, uint64(fee))
+	w.buf = appendBytes(w.buf, pair.Reserve0().Quotient().Bytes())
+	w.buf = appendBytes(w.buf, pair.Reserve1().Quotient().Bytes())
+	w.count++
+	return w.flush()
+}
+
+// WriteEntry writes the pair of the entry, or its removal
+func (w *Writer) WriteEntry(entry *Entry) error {
+	if entry.Pair == nil {
+		return w.RemovePair(entry.Address)
+	}
+	return w.WritePair(entry.Pair, entry.Fee)
+}
+
+// RemovePair writes the removal of the pair since the base block of a diff
+func (w *Writer) RemovePair(address common.Address) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if w.header.Kind != Diff {
+		return ErrRemovalInFull
+	}
+	w.buf = append(w.buf, tagRemoval)
+	w.buf = append(w.buf, address.Bytes()...)
+	w.count++
+	return w.flush()
+}
+
+// Close writes the footer and flushes the stream. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	w.buf = append(w.buf, tagEnd)
+	w.buf = appendUvarint(w.buf, w.count)
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := binary.Write(w.w, binary.BigEndian, w.crc.Sum32()); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// checksumReader hashes what it reads
+type checksumReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+}
+
+func (c *checksumReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(c.r, p)
+	c.crc.Write(p[:n])
+	return n, err
+}
+
+// Reader streams the entries of a snapshot
+type Reader struct {
+	header    Header
+	r         *checksumReader
+	tokens    []*core.Token
+	factories []*entities.PairOptions
+	count     uint64
+	done      bool
+}
+
+// NewReader reads the header of a stream
+func NewReader(r io.Reader) (*Reader, error) {
+	sr := &Reader{r: &checksumReader{r: bufio.NewReader(r), crc: crc32.New(castagnoli)}}
+	prefix := make([]byte, len(magic)+2)
+	if _, err := sr.r.Read(prefix); err != nil {
+		return nil, sr.invalid(err)
+	}
+	if string(prefix[:len(magic)]) != string(magic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidSnapshot)
+	}
+	sr.header.Version, sr.header.Kind = prefix[len(magic)], Kind(prefix[len(magic)+1])
+	if sr.header.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, sr.header.Version)
+	}
+	if sr.header.Kind != Full && sr.header.Kind != Diff {
+		return nil, fmt.Errorf("%w: kind %d", ErrInvalidSnapshot, sr.header.Kind)
+	}
+	chainID, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return nil, sr.invalid(err)
+	}
+	sr.header.ChainID = uint(chainID)
+	if sr.header.Block, err = binary.ReadUvarint(sr.r); err != nil {
+		return nil, sr.invalid(err)
+	}
+	if sr.header.Kind == Diff {
+		if sr.header.BaseBlock, err = binary.ReadUvarint(sr.r); err != nil {
+			return nil, sr.invalid(err)
+		}
+	}
+	return sr, nil
+}
+
+// Header returns the header read
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+func (r *Reader) invalid(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+}
+
+func (r *Reader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > 1<<16 {
+		return nil, fmt.Errorf("field of %d bytes", n)
+	}
+	b := make([]byte, n)
+	_, err = r.r.Read(b)
+	return b, err
+}
+
+func (r *Reader) readAddress() (common.Address, error) {
+	var address common.Address
+	_, err := r.r.Read(address[:])
+	return address, err
+}
+
+func (r *Reader) readToken() error {
+	address, err := r.readAddress()
+	if err != nil {
+		return err
+	}
+	decimals, err := r.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if decimals >= 255 {
+		return fmt.Errorf("token %s has %d decimals", address.Hex(), decimals)
+	}
+	symbol, err := r.readBytes()
+	if err != nil {
+		return err
+	}
+	name, err := r.readBytes()
+	if err != nil {
+		return err
+	}
+	r.tokens = append(r.tokens, core.NewToken(r.header.ChainID, address, uint(decimals), string(symbol), string(name)))
+	return nil
+}
+
+func (r *Reader) readFactory() error {
+	address, err := r.readAddress()
+	if err != nil {
+		return err
+	}
+	initCodeHash, err := r.readBytes()
+	if err != nil {
+		return err
+	}
+	r.factories = append(r.factories, &entities.PairOptions{Factory: address, InitCodeHash: initCodeHash})
+	return nil
+}
+
+func (r *Reader) readPair() (*Entry, error) {
+	address, err := r.readAddress()
+	if err != nil {
+		return nil, err
+	}
+	var indexes [4]uint64
+	for i := range indexes {
+		if indexes[i], err = binary.ReadUvarint(r.r); err != nil {
+			return nil, err
+		}
+	}
+	if indexes[0] >= uint64(len(r.tokens)) || indexes[1] >= uint64(len(r.tokens)) || indexes[2] >= uint64(len(r.factories)) {
+		return nil, fmt.Errorf("pair %s refers to an unknown token or factory", address.Hex())
+	}
+	if indexes[3] > math.MaxUint32 {
+		return nil, fmt.Errorf("pair %s has a fee of %d", address.Hex(), indexes[3])
+	}
+	var reserves [2]*big.Int
+	for i := range reserves {
+		b, err := r.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 32 {
+			return nil, fmt.Errorf("pair %s has a reserve of %d bytes", address.Hex(), len(b))
+		}
+		reserves[i] = new(big.Int).SetBytes(b)
+	}
+	factory := r.factories[indexes[2]]
+	pair, err := entities.NewPair(
+		core.FromRawAmount(r.tokens[indexes[0]], reserves[0]),
+		core.FromRawAmount(r.tokens[indexes[1]], reserves[1]),
+		&entities.PairOptions{Factory: factory.Factory, InitCodeHash: factory.InitCodeHash, Address: &address},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Address: address, Pair: pair, Fee: uint32(indexes[3])}, nil
+}
+
+// Next returns the next entry. Returns io.EOF at the end of a complete stream, and ErrChecksum if the stream is
+// corrupt, in which case the entries already returned must be discarded.
+func (r *Reader) Next() (*Entry, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	for {
+		tag, err := r.r.ReadByte()
+		if err != nil {
+			return nil, r.invalid(err)
+		}
+		switch tag {
+		case tagToken:
+			err = r.readToken()
+		case tagFactory:
+			err = r.readFactory()
+		case tagPair:
+			entry, err := r.readPair()
+			if err != nil {
+				return nil, r.invalid(err)
+			}
+			r.count++
+			return entry, nil
+		case tagRemoval:
+			if r.header.Kind != Diff {
+				return nil, r.invalid(ErrRemovalInFull)
+			}
+			address, err := r.readAddress()
+			if err != nil {
+				return nil, r.invalid(err)
+			}
+			r.count++
+			return &Entry{Address: address}, nil
+		case tagEnd:
+			return nil, r.end()
+		default:
+			return nil, fmt.Errorf("%w: unknown record %d", ErrInvalidSnapshot, tag)
+		}
+		if err != nil {
+			return nil, r.invalid(err)
+		}
+	}
+}
+
+func (r *Reader) end() error {
+	count, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return r.invalid(err)
+	}
+	sum := r.r.crc.Sum32()
+	var expected uint32
+	if err := binary.Read(r.r.r, binary.BigEndian, &expected); err != nil {
+		return r.invalid(err)
+	}
+	if sum != expected || count != r.count {
+		return ErrChecksum
+	}
+	r.done = true
+	return io.EOF
+}
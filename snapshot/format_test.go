@@ -0,0 +1,121 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/snapshot"
+)
+
+var (
+	token0 = core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000001"), 18, "t0", "Token 0")
+	token1 = core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000002"), 6, "t1", "Token 1")
+	token2 = core.NewToken(1, common.HexToAddress("0x0000000000000000000000000000000000000003"), 8, "t2", "")
+)
+
+func newPair(t *testing.T, a, b *core.Token, reserveA, reserveB int64, options *entities.PairOptions) *entities.Pair {
+	pair, err := entities.NewPair(core.FromRawAmount(a, big.NewInt(reserveA)), core.FromRawAmount(b, big.NewInt(reserveB)), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+func TestWriterReader(t *testing.T) {
+	fork := &entities.PairOptions{Factory: common.HexToAddress("0x00000000000000000000000000000000000000ff"), InitCodeHash: []byte{1, 2, 3}}
+	pairs := []*entities.Pair{
+		newPair(t, token0, token1, 1000, 2000, nil),
+		newPair(t, token1, token2, 3000, 4000, nil),
+		newPair(t, token0, token2, 5000, 6000, fork),
+	}
+
+	var buf bytes.Buffer
+	w, err := snapshot.NewWriter(&buf, snapshot.Header{Kind: snapshot.Full, ChainID: 1, Block: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pair := range pairs {
+		if err := w.WritePair(pair, uint32(30+i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.RemovePair(pairs[0].Address); !errors.Is(err, snapshot.ErrRemovalInFull) {
+		t.Errorf("expect[%v], but got[%v]", snapshot.ErrRemovalInFull, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePair(pairs[0], 30); !errors.Is(err, snapshot.ErrClosed) {
+		t.Errorf("expect[%v], but got[%v]", snapshot.ErrClosed, err)
+	}
+	data := buf.Bytes()
+
+	r, err := snapshot.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := r.Header(); h.Version != snapshot.Version || h.Kind != snapshot.Full || h.ChainID != 1 || h.Block != 100 {
+		t.Errorf("expect[full snapshot of block 100], but got[%+v]", h)
+	}
+	for i, pair := range pairs {
+		entry, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := entry.Pair
+		if entry.Address != pair.Address || got.Address != pair.Address || entry.Fee != uint32(30+i) ||
+			got.Reserve0().Quotient().Cmp(pair.Reserve0().Quotient()) != 0 || got.Reserve1().Quotient().Cmp(pair.Reserve1().Quotient()) != 0 ||
+			!got.Token0().Equal(pair.Token0()) || got.Token0().Decimals() != pair.Token0().Decimals() || got.Token0().Name() != pair.Token0().Name() ||
+			got.Options.Factory != pair.Options.Factory || !bytes.Equal(got.Options.InitCodeHash, pair.Options.InitCodeHash) {
+			t.Errorf("pair #%d: expect[%+v], but got[%+v]", i, pair, got)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expect[%v], but got[%v]", io.EOF, err)
+	}
+
+	// corruption
+	corrupt := func(f func(b []byte) []byte) error {
+		r, err := snapshot.NewReader(bytes.NewReader(f(append([]byte{}, data...))))
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err := r.Next(); err != nil {
+				return err
+			}
+		}
+	}
+	tests := []struct {
+		Name    string
+		Corrupt func(b []byte) []byte
+		Err     error
+	}{
+		{"reserve", func(b []byte) []byte { b[len(b)-10]++; return b }, snapshot.ErrChecksum},
+		{"checksum", func(b []byte) []byte { b[len(b)-1]++; return b }, snapshot.ErrChecksum},
+		{"truncated", func(b []byte) []byte { return b[:len(b)-20] }, snapshot.ErrInvalidSnapshot},
+		{"magic", func(b []byte) []byte { b[0] = 'X'; return b }, snapshot.ErrInvalidSnapshot},
+		{"version", func(b []byte) []byte { b[4] = 9; return b }, snapshot.ErrVersion},
+		{"empty", func(b []byte) []byte { return nil }, snapshot.ErrInvalidSnapshot},
+		{"decimals", func(b []byte) []byte { b[bytes.Index(b, token0.Address.Bytes())+20] = 0xff; return b }, snapshot.ErrInvalidSnapshot},
+		{"fee", func(b []byte) []byte {
+			// the fee of the last pair follows its address and 3 single byte indexes
+			i := bytes.Index(b, pairs[2].Address.Bytes()) + 23
+			fee := make([]byte, binary.MaxVarintLen64)
+			fee = fee[:binary.PutUvarint(fee, 1<<32+32)]
+			return append(append(append([]byte{}, b[:i]...), fee...), b[i+1:]...)
+		}, snapshot.ErrInvalidSnapshot},
+	}
+	for _, test := range tests {
+		if err := corrupt(test.Corrupt); !errors.Is(err, test.Err) {
+			t.Errorf("%s: expect[%v], but got[%v]", test.Name, test.Err, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var (
+	ErrBaseMismatch = errors.New("diff does not apply to the snapshot")
+	ErrNotFull      = errors.New("stream is not a full snapshot")
+	ErrNotDiff      = errors.New("stream is not a diff")
+)
+
+// Snapshot is the set of pairs of a chain at a block, or a diff between two such sets
+type Snapshot struct {
+	Header
+	Entries map[common.Address]*Entry
+}
+
+// New creates a full snapshot of the pairs, all with DefaultFee
+func New(chainID uint, block uint64, pairs ...*entities.Pair) *Snapshot {
+	s := &Snapshot{Header: Header{Version: Version, Kind: Full, ChainID: chainID, Block: block}, Entries: map[common.Address]*Entry{}}
+	for _, pair := range pairs {
+		s.Entries[pair.Address] = &Entry{Address: pair.Address, Pair: pair, Fee: DefaultFee}
+	}
+	return s
+}
+
+// Pairs returns the pairs sorted by address
+func (s *Snapshot) Pairs() []*entities.Pair {
+	pairs := make([]*entities.Pair, 0, len(s.Entries))
+	for _, entry := range s.sorted() {
+		if entry.Pair != nil {
+			pairs = append(pairs, entry.Pair)
+		}
+	}
+	return pairs
+}
+
+func (s *Snapshot) sorted() []*Entry {
+	entries := make([]*Entry, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].Address.Bytes(), entries[j].Address.Bytes()) < 0
+	})
+	return entries
+}
+
+// WriteTo writes the snapshot, entries sorted by address
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	sw, err := NewWriter(counter, s.Header)
+	if err != nil {
+		return counter.n, err
+	}
+	for _, entry := range s.sorted() {
+		if err := sw.WriteEntry(entry); err != nil {
+			return counter.n, err
+		}
+	}
+	err = sw.Close()
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Read reads a whole snapshot or diff, verifying its checksum
+func Read(r io.Reader) (*Snapshot, error) {
+	sr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{Header: sr.Header(), Entries: map[common.Address]*Entry{}}
+	for {
+		entry, err := sr.Next()
+		if errors.Is(err, io.EOF) {
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.Entries[entry.Address] = entry
+	}
+}
+
+func equal(a, b *Entry) bool {
+	factoryA, hashA := pairOptions(a.Pair)
+	factoryB, hashB := pairOptions(b.Pair)
+	return a.Fee == b.Fee && factoryA == factoryB && bytes.Equal(hashA, hashB) &&
+		a.Pair.Token0().Equal(b.Pair.Token0()) && a.Pair.Token1().Equal(b.Pair.Token1()) &&
+		a.Pair.Reserve0().Quotient().Cmp(b.Pair.Reserve0().Quotient()) == 0 &&
+		a.Pair.Reserve1().Quotient().Cmp(b.Pair.Reserve1().Quotient()) == 0
+}
+
+// DiffOf returns the diff turning the full snapshot from into to: the pairs added or changed, and the removed ones
+func DiffOf(from, to *Snapshot) (*Snapshot, error) {
+	if from.Kind != Full || to.Kind != Full {
+		return nil, ErrNotFull
+	}
+	if from.ChainID != to.ChainID {
+		return nil, fmt.Errorf("%w: chain %d, diff of chain %d", ErrBaseMismatch, from.ChainID, to.ChainID)
+	}
+	diff := &Snapshot{
+		Header:  Header{Version: Version, Kind: Diff, ChainID: to.ChainID, Block: to.Block, BaseBlock: from.Block},
+		Entries: map[common.Address]*Entry{},
+	}
+	for address, entry := range to.Entries {
+		if known, ok := from.Entries[address]; !ok || !equal(known, entry) {
+			diff.Entries[address] = entry
+		}
+	}
+	for address := range from.Entries {
+		if _, ok := to.Entries[address]; !ok {
+			diff.Entries[address] = &Entry{Address: address}
+		}
+	}
+	return diff, nil
+}
+
+// Apply returns the full snapshot after the diff. The snapshot is unchanged.
+func (s *Snapshot) Apply(diff *Snapshot) (*Snapshot, error) {
+	if s.Kind != Full {
+		return nil, ErrNotFull
+	}
+	if diff.Kind != Diff {
+		return nil, ErrNotDiff
+	}
+	if diff.ChainID != s.ChainID || diff.BaseBlock != s.Block {
+		return nil, fmt.Errorf("%w: block %d of chain %d, diff of block %d of chain %d",
+			ErrBaseMismatch, s.Block, s.ChainID, diff.BaseBlock, diff.ChainID)
+	}
+	next := &Snapshot{Header: s.Header, Entries: make(map[common.Address]*Entry, len(s.Entries))}
+	next.Block = diff.Block
+	for address, entry := range s.Entries {
+		next.Entries[address] = entry
+	}
+	for address, entry := range diff.Entries {
+		if entry.Pair == nil {
+			delete(next.Entries, address)
+			continue
+		}
+		next.Entries[address] = entry
+	}
+	return next, nil
+}
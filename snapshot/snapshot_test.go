@@ -0,0 +1,78 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/vaulverin/uniswapv2-sdk/snapshot"
+)
+
+func TestDiff(t *testing.T) {
+	pair_0_1 := newPair(t, token0, token1, 1000, 2000, nil)
+	pair_1_2 := newPair(t, token1, token2, 3000, 4000, nil)
+	pair_0_2 := newPair(t, token0, token2, 5000, 6000, nil)
+	from := snapshot.New(1, 100, pair_0_1, pair_1_2)
+	to := snapshot.New(1, 110, newPair(t, token0, token1, 1100, 1900, nil), pair_1_2, pair_0_2)
+	delete(to.Entries, pair_1_2.Address)
+
+	var buf bytes.Buffer
+	n, err := from.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("expect[%d bytes], but got[%d %v]", buf.Len(), n, err)
+	}
+	read, err := snapshot.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Pairs()) != 2 || read.Block != 100 {
+		t.Errorf("expect[2 pairs at 100], but got[%d at %d]", len(read.Pairs()), read.Block)
+	}
+
+	diff, err := snapshot.DiffOf(read, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := diff.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err = snapshot.Read(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Kind != snapshot.Diff || diff.BaseBlock != 100 || diff.Block != 110 || len(diff.Entries) != 3 {
+		t.Errorf("expect[3 entries from 100 to 110], but got[%d from %d to %d]", len(diff.Entries), diff.BaseBlock, diff.Block)
+	}
+	if entry := diff.Entries[pair_1_2.Address]; entry == nil || entry.Pair != nil {
+		t.Errorf("expect[removal], but got[%+v]", entry)
+	}
+
+	applied, err := read.Apply(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.Block != 110 || len(applied.Entries) != 2 || read.Block != 100 || len(read.Entries) != 2 {
+		t.Errorf("expect[2 pairs at 110], but got[%d at %d]", len(applied.Entries), applied.Block)
+	}
+	if again, _ := snapshot.DiffOf(applied, to); len(again.Entries) != 0 {
+		t.Errorf("expect[no changes], but got[%d]", len(again.Entries))
+	}
+	got := applied.Entries[pair_0_1.Address].Pair
+	if got.Reserve0().Quotient().Int64() != 1100 || got.Reserve1().Quotient().Int64() != 1900 {
+		t.Errorf("expect[1100 1900], but got[%s %s]", got.Reserve0().Quotient(), got.Reserve1().Quotient())
+	}
+
+	if _, err := applied.Apply(diff); !errors.Is(err, snapshot.ErrBaseMismatch) {
+		t.Errorf("expect[%v], but got[%v]", snapshot.ErrBaseMismatch, err)
+	}
+	if _, err := read.Apply(read); !errors.Is(err, snapshot.ErrNotDiff) {
+		t.Errorf("expect[%v], but got[%v]", snapshot.ErrNotDiff, err)
+	}
+	if _, err := snapshot.DiffOf(diff, to); !errors.Is(err, snapshot.ErrNotFull) {
+		t.Errorf("expect[%v], but got[%v]", snapshot.ErrNotFull, err)
+	}
+	pairs := applied.Pairs()
+	if len(pairs) != 2 || bytes.Compare(pairs[0].Address.Bytes(), pairs[1].Address.Bytes()) >= 0 {
+		t.Errorf("expect[2 pairs sorted by address], but got[%v]", pairs)
+	}
+}
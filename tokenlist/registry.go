@@ -0,0 +1,134 @@
+package tokenlist
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+)
+
+var (
+	ErrConflictingToken = errors.New("token listed with conflicting metadata")
+)
+
+// Registry indexes tokens from one or more lists by chain ID, address and symbol
+type Registry struct {
+	byAddress map[uint]map[common.Address]*Token
+	bySymbol  map[uint]map[string][]*Token
+	ordered   map[uint][]*Token // tokens per chain in insertion order
+}
+
+// PairCandidate is a possible pair of two registry tokens
+type PairCandidate struct {
+	TokenA  *Token
+	TokenB  *Token
+	Address common.Address // CREATE2 pair address, whether or not the pair is deployed
+}
+
+// NewRegistry creates a Registry from the token lists.
+// A token present in several lists is kept once, its tags and extensions are merged.
+func NewRegistry(lists ...*TokenList) (*Registry, error) {
+	r := &Registry{
+		byAddress: make(map[uint]map[common.Address]*Token),
+		bySymbol:  make(map[uint]map[string][]*Token),
+		ordered:   make(map[uint][]*Token),
+	}
+	for _, list := range lists {
+		for _, info := range list.Tokens {
+			if err := r.Add(NewToken(info, list.Tags)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// Add indexes a copy of the token. Adding an already known token merges its tags and extensions into the copy.
+func (r *Registry) Add(token *Token) error {
+	chainID := token.ChainId()
+	if r.byAddress[chainID] == nil {
+		r.byAddress[chainID] = make(map[common.Address]*Token)
+		r.bySymbol[chainID] = make(map[string][]*Token)
+	}
+
+	if known, ok := r.byAddress[chainID][token.Address]; ok {
+		if known.Decimals() != token.Decimals() {
+			return ErrConflictingToken
+		}
+		for i, id := range token.TagIDs {
+			if !known.HasTag(id) {
+				var tag TagDefinition
+				if i < len(token.Tags) {
+					tag = token.Tags[i]
+				}
+				known.TagIDs = append(known.TagIDs, id)
+				known.Tags = append(known.Tags, tag)
+			}
+		}
+		for key, value := range token.Extensions {
+			if known.Extensions == nil {
+				known.Extensions = make(map[string]interface{})
+			}
+			if _, ok := known.Extensions[key]; !ok {
+				known.Extensions[key] = value
+			}
+		}
+		return nil
+	}
+
+	token = token.clone()
+	r.byAddress[chainID][token.Address] = token
+	symbol := strings.ToUpper(token.Symbol())
+	r.bySymbol[chainID][symbol] = append(r.bySymbol[chainID][symbol], token)
+	r.ordered[chainID] = append(r.ordered[chainID], token)
+	return nil
+}
+
+// ChainIDs returns the chains that have tokens in ascending order
+func (r *Registry) ChainIDs() []uint {
+	ids := make([]uint, 0, len(r.ordered))
+	for id := range r.ordered {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Tokens returns the tokens of a chain
+func (r *Registry) Tokens(chainID uint) []*Token {
+	return r.ordered[chainID]
+}
+
+// ByAddress returns the token at the address on the chain
+func (r *Registry) ByAddress(chainID uint, address common.Address) (*Token, bool) {
+	token, ok := r.byAddress[chainID][address]
+	return token, ok
+}
+
+// BySymbol returns the tokens of the chain with the symbol, compared case insensitively.
+// Symbols are not unique, so several tokens may be returned.
+func (r *Registry) BySymbol(chainID uint, symbol string) []*Token {
+	return r.bySymbol[chainID][strings.ToUpper(symbol)]
+}
+
+// PairAddresses returns a candidate pair for every combination of tokens on the chain
+func (r *Registry) PairAddresses(chainID uint, factory common.Address, initCodeHash []byte) ([]*PairCandidate, error) {
+	tokens := r.ordered[chainID]
+	candidates := make([]*PairCandidate, 0, len(tokens)*(len(tokens)-1)/2)
+	for i := 0; i < len(tokens); i++ {
+		for j := i + 1; j < len(tokens); j++ {
+			address, err := entities.GetAddress(tokens[i].Token, tokens[j].Token, factory, initCodeHash)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, &PairCandidate{
+				TokenA:  tokens[i],
+				TokenB:  tokens[j],
+				Address: address,
+			})
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,284 @@
+package tokenlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	MaxTokens          = 10000
+	MaxTagsPerToken    = 10
+	MaxExtensions      = 10
+	MaxNameLength      = 40
+	MaxSymbolLength    = 20
+	MaxListNameLength  = 30
+	MaxDecimals        = 254 // core.NewToken refuses 255 decimals
+	MaxTagIDLength     = 10
+	MaxTagNameLength   = 20
+	MaxTagDescLength   = 200
+	MaxKeywords        = 20
+	MaxKeywordLength   = 20
+	MaxLogoURILength   = 1024
+	MaxListTagsCount   = 20
+	MaxExtensionKeyLen = 40
+)
+
+var (
+	ErrInvalidTokenList = errors.New("invalid token list")
+
+	addressPattern  = regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`)
+	symbolPattern   = regexp.MustCompile(`^\S+$`)
+	namePattern     = regexp.MustCompile(`^[ \w.'+\-%/À-ÖØ-öø-ÿ:&\[\]()]+$`)
+	listNamePattern = regexp.MustCompile(`^[\w ]+$`)
+	tagIDPattern    = regexp.MustCompile(`^[\w]+$`)
+)
+
+// Version of a token list, following semver
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// TagDefinition describes a tag referenced by the tokens of a list
+type TagDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TokenInfo is a token entry as it appears in a token list
+type TokenInfo struct {
+	ChainID    uint                   `json:"chainId"`
+	Address    string                 `json:"address"`
+	Decimals   uint                   `json:"decimals"`
+	Symbol     string                 `json:"symbol"`
+	Name       string                 `json:"name"`
+	LogoURI    string                 `json:"logoURI,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// TokenList follows the https://tokenlists.org schema
+type TokenList struct {
+	Name      string                   `json:"name"`
+	Timestamp string                   `json:"timestamp"`
+	Version   Version                  `json:"version"`
+	Tokens    []*TokenInfo             `json:"tokens"`
+	Tags      map[string]TagDefinition `json:"tags,omitempty"`
+	LogoURI   string                   `json:"logoURI,omitempty"`
+	Keywords  []string                 `json:"keywords,omitempty"`
+}
+
+// ValidationError points to a single schema violation
+type ValidationError struct {
+	Path    string // JSON path of the offending value, e.g. tokens[3].symbol
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors collects all schema violations found in a list
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return ErrInvalidTokenList.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error {
+	return ErrInvalidTokenList
+}
+
+// Parse decodes and validates a token list
+func Parse(data []byte) (*TokenList, error) {
+	var list TokenList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTokenList, err)
+	}
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Load reads, decodes and validates a token list
+func Load(r io.Reader) (*TokenList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Validate checks the list against the token list schema.
+// Returns ValidationErrors listing every violation, or nil.
+// nolint gocyclo
+func (l *TokenList) Validate() error {
+	var errs ValidationErrors
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if l.Name == "" || len(l.Name) > MaxListNameLength || !listNamePattern.MatchString(l.Name) {
+		fail("name", "must be 1-%d word characters or spaces", MaxListNameLength)
+	}
+	if l.Timestamp == "" {
+		fail("timestamp", "is required")
+	}
+	if l.Version.Major < 0 || l.Version.Minor < 0 || l.Version.Patch < 0 {
+		fail("version", "must not be negative")
+	}
+	if len(l.LogoURI) > MaxLogoURILength {
+		fail("logoURI", "exceeds %d characters", MaxLogoURILength)
+	}
+	if len(l.Keywords) > MaxKeywords {
+		fail("keywords", "exceeds %d items", MaxKeywords)
+	}
+	for i, keyword := range l.Keywords {
+		if keyword == "" || len(keyword) > MaxKeywordLength {
+			fail(fmt.Sprintf("keywords[%d]", i), "must be 1-%d characters", MaxKeywordLength)
+		}
+	}
+	if len(l.Tags) > MaxListTagsCount {
+		fail("tags", "exceeds %d definitions", MaxListTagsCount)
+	}
+	for id, tag := range l.Tags {
+		path := "tags." + id
+		if len(id) > MaxTagIDLength || !tagIDPattern.MatchString(id) {
+			fail(path, "tag id must be 1-%d word characters", MaxTagIDLength)
+		}
+		if tag.Name == "" || len(tag.Name) > MaxTagNameLength {
+			fail(path+".name", "must be 1-%d characters", MaxTagNameLength)
+		}
+		if tag.Description == "" || len(tag.Description) > MaxTagDescLength {
+			fail(path+".description", "must be 1-%d characters", MaxTagDescLength)
+		}
+	}
+
+	if len(l.Tokens) == 0 || len(l.Tokens) > MaxTokens {
+		fail("tokens", "must contain 1-%d tokens", MaxTokens)
+	}
+	seen := make(map[string]int, len(l.Tokens))
+	for i, token := range l.Tokens {
+		path := fmt.Sprintf("tokens[%d]", i)
+		if token == nil {
+			fail(path, "is null")
+			continue
+		}
+		if token.ChainID == 0 {
+			fail(path+".chainId", "must be positive")
+		}
+		if !addressPattern.MatchString(token.Address) {
+			fail(path+".address", "%q is not a hex address", token.Address)
+		} else {
+			key := fmt.Sprintf("%d:%s", token.ChainID, strings.ToLower(token.Address))
+			if j, ok := seen[key]; ok {
+				fail(path+".address", "duplicates tokens[%d] on chain %d", j, token.ChainID)
+			}
+			seen[key] = i
+		}
+		if token.Decimals > MaxDecimals {
+			fail(path+".decimals", "must be at most %d", MaxDecimals)
+		}
+		if token.Symbol == "" || len(token.Symbol) > MaxSymbolLength || !symbolPattern.MatchString(token.Symbol) {
+			fail(path+".symbol", "must be 1-%d non-space characters", MaxSymbolLength)
+		}
+		if token.Name == "" || len(token.Name) > MaxNameLength || !namePattern.MatchString(token.Name) {
+			fail(path+".name", "must be 1-%d name characters", MaxNameLength)
+		}
+		if len(token.LogoURI) > MaxLogoURILength {
+			fail(path+".logoURI", "exceeds %d characters", MaxLogoURILength)
+		}
+		if len(token.Tags) > MaxTagsPerToken {
+			fail(path+".tags", "exceeds %d items", MaxTagsPerToken)
+		}
+		for j, tag := range token.Tags {
+			if _, ok := l.Tags[tag]; !ok {
+				fail(fmt.Sprintf("%s.tags[%d]", path, j), "tag %q is not defined by the list", tag)
+			}
+		}
+		if len(token.Extensions) > MaxExtensions {
+			fail(path+".extensions", "exceeds %d keys", MaxExtensions)
+		}
+		for key := range token.Extensions {
+			if len(key) > MaxExtensionKeyLen {
+				fail(path+".extensions", "key %q exceeds %d characters", key, MaxExtensionKeyLen)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Token is a core token enriched with its token list metadata
+type Token struct {
+	*core.Token
+	LogoURI    string
+	Tags       []TagDefinition
+	TagIDs     []string
+	Extensions map[string]interface{}
+}
+
+// NewToken converts a list entry into a Token, resolving its tags against the list definitions
+func NewToken(info *TokenInfo, tags map[string]TagDefinition) *Token {
+	token := &Token{
+		Token:      core.NewToken(info.ChainID, common.HexToAddress(info.Address), info.Decimals, info.Symbol, info.Name),
+		LogoURI:    info.LogoURI,
+		TagIDs:     append([]string(nil), info.Tags...),
+		Extensions: copyExtensions(info.Extensions),
+	}
+	for _, id := range info.Tags {
+		token.Tags = append(token.Tags, tags[id])
+	}
+	return token
+}
+
+// copyExtensions returns a copy of the extensions, the values are shared
+func copyExtensions(extensions map[string]interface{}) map[string]interface{} {
+	if extensions == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(extensions))
+	for key, value := range extensions {
+		copied[key] = value
+	}
+	return copied
+}
+
+// clone returns a copy of the token that shares no tags or extensions with it
+func (t *Token) clone() *Token {
+	c := *t
+	c.Tags = append([]TagDefinition(nil), t.Tags...)
+	c.TagIDs = append([]string(nil), t.TagIDs...)
+	c.Extensions = copyExtensions(t.Extensions)
+	return &c
+}
+
+// HasTag returns true if the token is labeled with the tag id
+func (t *Token) HasTag(id string) bool {
+	for _, tag := range t.TagIDs {
+		if tag == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package tokenlist_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vaulverin/uniswapv2-sdk/entities"
+	"github.com/vaulverin/uniswapv2-sdk/tokenlist"
+)
+
+const testList = `{
+	"name": "Test List",
+	"timestamp": "2021-01-01T00:00:00.000Z",
+	"version": {"major": 1, "minor": 2, "patch": 3},
+	"tags": {
+		"stablecoin": {"name": "Stablecoin", "description": "Tokens pegged to a fiat currency"}
+	},
+	"tokens": [
+		{"chainId": 1, "address": "0x6B175474E89094C44Da98b954EedeAC495271d0F", "decimals": 18, "symbol": "DAI", "name": "Dai Stablecoin", "tags": ["stablecoin"]},
+		{"chainId": 1, "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "decimals": 6, "symbol": "USDC", "name": "USD Coin", "tags": ["stablecoin"], "extensions": {"bridgeInfo": "native"}},
+		{"chainId": 1, "address": "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "decimals": 18, "symbol": "WETH", "name": "Wrapped Ether"},
+		{"chainId": 137, "address": "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "decimals": 6, "symbol": "USDC", "name": "USD Coin (PoS)"}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	list, err := tokenlist.Parse([]byte(testList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Version.String() != "1.2.3" {
+		t.Errorf("expect[1.2.3], but got[%s]", list.Version)
+	}
+	if len(list.Tokens) != 4 {
+		t.Errorf("expect[4], but got[%d]", len(list.Tokens))
+	}
+
+	// reports every violation
+	{
+		invalid := strings.Replace(testList, `"symbol": "WETH"`, `"symbol": ""`, 1)
+		invalid = strings.Replace(invalid, `"decimals": 6, "symbol": "USDC", "name": "USD Coin",`, `"decimals": 300, "symbol": "USDC", "name": "USD Coin",`, 1)
+		invalid = strings.Replace(invalid, `"tags": ["stablecoin"]}`, `"tags": ["unknown"]}`, 1)
+		_, err := tokenlist.Parse([]byte(invalid))
+		if !errors.Is(err, tokenlist.ErrInvalidTokenList) {
+			t.Fatalf("expect[%+v], but got[%+v]", tokenlist.ErrInvalidTokenList, err)
+		}
+		var errs tokenlist.ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expect ValidationErrors, but got[%T]", err)
+		}
+		paths := map[string]bool{}
+		for _, e := range errs {
+			paths[e.Path] = true
+		}
+		for _, path := range []string{"tokens[0].tags[0]", "tokens[1].decimals", "tokens[2].symbol"} {
+			if !paths[path] {
+				t.Errorf("expect violation at %s, but got[%v]", path, err)
+			}
+		}
+	}
+
+	// rejects duplicated tokens
+	{
+		invalid := strings.Replace(testList, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "0x6b175474e89094c44da98b954eedeac495271d0f", 1)
+		_, err := tokenlist.Parse([]byte(invalid))
+		if err == nil || !strings.Contains(err.Error(), "duplicates tokens[0]") {
+			t.Errorf("expect duplicate violation, but got[%v]", err)
+		}
+	}
+
+	// rejects malformed json
+	{
+		_, err := tokenlist.Parse([]byte(`{"name": 1}`))
+		if !errors.Is(err, tokenlist.ErrInvalidTokenList) {
+			t.Errorf("expect[%+v], but got[%+v]", tokenlist.ErrInvalidTokenList, err)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	list, err := tokenlist.Load(strings.NewReader(testList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := tokenlist.NewRegistry(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := registry.ChainIDs(); len(ids) != 2 || ids[0] != 1 || ids[1] != 137 {
+		t.Errorf("expect[[1 137]], but got[%v]", ids)
+	}
+
+	// indexes by address and keeps metadata
+	{
+		usdc, ok := registry.ByAddress(1, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+		if !ok {
+			t.Fatal("usdc is not indexed")
+		}
+		if usdc.Decimals() != 6 || usdc.Symbol() != "USDC" {
+			t.Errorf("wrong token %+v", usdc.Token)
+		}
+		if !usdc.HasTag("stablecoin") || usdc.Tags[0].Name != "Stablecoin" {
+			t.Errorf("wrong tags %+v", usdc.Tags)
+		}
+		if usdc.Extensions["bridgeInfo"] != "native" {
+			t.Errorf("wrong extensions %+v", usdc.Extensions)
+		}
+	}
+
+	// indexes by symbol per chain
+	{
+		tokens := registry.BySymbol(137, "usdc")
+		if len(tokens) != 1 || tokens[0].ChainId() != 137 {
+			t.Errorf("wrong tokens %+v", tokens)
+		}
+	}
+
+	// merges tokens from several lists
+	{
+		replacer := strings.NewReplacer(
+			`"symbol": "WETH", "name": "Wrapped Ether"`, `"symbol": "WETH", "name": "Wrapped Ether", "tags": ["stablecoin"]`,
+			`"extensions": {"bridgeInfo": "native"}`, `"extensions": {"bridgeInfo": "native", "coingeckoId": "usd-coin"}`,
+		)
+		other, err := tokenlist.Parse([]byte(replacer.Replace(testList)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged, err := tokenlist.NewRegistry(list, other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(merged.Tokens(1)) != 3 {
+			t.Errorf("expect[3], but got[%d]", len(merged.Tokens(1)))
+		}
+		weth, _ := merged.ByAddress(1, common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"))
+		if !weth.HasTag("stablecoin") {
+			t.Errorf("tags were not merged %+v", weth.TagIDs)
+		}
+		usdc, _ := merged.ByAddress(1, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+		if usdc.Extensions["coingeckoId"] != "usd-coin" {
+			t.Errorf("extensions were not merged %+v", usdc.Extensions)
+		}
+		// the lists are left as parsed
+		if len(list.Tokens[2].Tags) != 0 || len(list.Tokens[1].Extensions) != 1 {
+			t.Errorf("the first list was modified %+v %+v", list.Tokens[2].Tags, list.Tokens[1].Extensions)
+		}
+
+		// tags without a definition merge as empty definitions
+		if err := merged.Add(&tokenlist.Token{Token: weth.Token, TagIDs: []string{"bridged"}}); err != nil {
+			t.Fatal(err)
+		}
+		if !weth.HasTag("bridged") || len(weth.Tags) != len(weth.TagIDs) {
+			t.Errorf("wrong tags %+v %+v", weth.TagIDs, weth.Tags)
+		}
+	}
+
+	// generates a candidate for every combination
+	{
+		candidates, err := registry.PairAddresses(1, entities.FactoryAddress, entities.InitCodeHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(candidates) != 3 {
+			t.Fatalf("expect[3], but got[%d]", len(candidates))
+		}
+		// DAI/USDC
+		expect := common.HexToAddress("0xAE461cA67B15dc8dc81CE7615e0320dA1A9aB8D5")
+		if candidates[0].Address != expect {
+			t.Errorf("expect[%+v], but got[%+v]", expect, candidates[0].Address)
+		}
+	}
+}